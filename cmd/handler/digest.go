@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"JillBot/internal/models"
+	"JillBot/pkg/sender"
+	"context"
+	"log"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// sendDigests ставит в очередь отправки утренние сводки, время которых подошло.
+func (h *Handler) sendDigests(bot *telego.Bot, out *sender.Sender) {
+	digests, err := h.BotSrv.GetDueDigests(context.TODO())
+	if err != nil {
+		log.Printf("Ошибка при подготовке сводок: %v", err)
+		return
+	}
+	for _, digest := range digests {
+		msg := tu.Message(tu.ID(digest.ChatID), digest.Text).WithReplyMarkup(createDigestButtons(digest))
+		out.Enqueue(digest.ChatID, func() error {
+			_, err := bot.SendMessage(msg)
+			return err
+		}, func(err error) {
+			if err != nil {
+				log.Printf("Ошибка отправки сводки: %v", err)
+				h.suspendIfForbidden(digest.ChatID, err)
+			}
+		})
+	}
+}
+
+func createDigestButtons(digest models.Digest) *telego.InlineKeyboardMarkup {
+	return tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("📋 Весь список").WithCallbackData("dg:list"),
+			tu.InlineKeyboardButton("➡️ Перенести день").WithCallbackData("dg:postpone:"+digest.Day),
+		),
+	)
+}
@@ -1,9 +1,15 @@
 package handler
 
 import (
+	"JillBot/internal/models"
 	"JillBot/internal/service"
+	"JillBot/pkg/tzresolver"
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
@@ -12,6 +18,12 @@ import (
 
 //go:generate mockgen -source=handler.go -destination=mocks/mock.go
 
+// snoozePromptID находит ID напоминания в вопросе "Когда напомнить еще раз?", на который ответил пользователь.
+var snoozePromptID = regexp.MustCompile(`ID: ([0-9a-f]{24})`)
+
+// editPromptNum находит номер напоминания в сообщении "Изменение напоминания", на которое ответил пользователь.
+var editPromptNum = regexp.MustCompile(`^✏️ Изменение напоминания №(\d+)`)
+
 type BotHandler interface {
 	Handle(handler th.Handler, predicates ...th.Predicate)
 }
@@ -36,10 +48,44 @@ func (h *Handler) InitRoutes() {
 		}
 		bot.SendMessage(&response)
 		requestLocation(bot, chatID)
+		h.offerResume(bot, update.Message.Chat.ID)
 	}, th.CommandEqual("start"))
 
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Бота заблокировали, удалили из группы или вернули
+		member := update.MyChatMember
+		switch {
+		case chatLeft(member.NewChatMember.MemberStatus()):
+			h.suspendChat(member.Chat.ID)
+		case chatLeft(member.OldChatMember.MemberStatus()) && member.Chat.Type != telego.ChatTypePrivate:
+			// В личном чате после разблокировки придет /start, а в группу бота просто добавляют обратно
+			h.offerResume(bot, member.Chat.ID)
+		}
+	}, th.AnyMyChatMember())
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Возобновление напоминаний после паузы
+		query := update.CallbackQuery
+		chat := query.Message
+		chatID := chat.GetChat().ID
+		text := "Хорошо, напоминания пока на паузе. Чтобы возобновить их, отправь /start"
+		if query.Data == "rs:resume" {
+			var err error
+			text, err = h.BotSrv.ResumeChat(context.TODO(), chatID)
+			if err != nil {
+				bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Упс, " + err.Error()))
+				return
+			}
+		}
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: chat.GetMessageID(),
+			Text:      text,
+		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.CallbackDataPrefix("rs:"))
+
 	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Настройка таймзоны
 		chatID := tu.ID(update.Message.Chat.ID)
+		oldZone := h.currentZone(update.Message.Chat.ID)
 		err := h.BotSrv.SetTimezone(context.TODO(), update.Message.Chat.ID, update.Message.Location.Latitude, update.Message.Location.Longitude)
 		var text string
 		if err != nil {
@@ -52,6 +98,7 @@ func (h *Handler) InitRoutes() {
 			ChatID: chatID,
 		}
 		bot.SendMessage(&response)
+		h.askReanchor(bot, update.Message.Chat.ID, oldZone)
 	}, func(update telego.Update) bool {
 		if update.Message != nil && update.Message.Location != nil {
 			return true
@@ -77,20 +124,18 @@ func (h *Handler) InitRoutes() {
 
 	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Добавление напоминания
 		chatID := tu.ID(update.Message.Chat.ID)
-		tz, err := h.BotSrv.GetTimezone(context.TODO(), update.Message.Chat.ID)
-		if err != nil {
-			response := telego.SendMessageParams{
-				ChatID: chatID,
-				Text:   "Я не знаю вашего часового пояса. Ты можешь его добавить через /setlocation",
-			}
-			bot.SendMessage(&response)
-			return
+		var tz *models.ChatTimezone
+		if chatTZ, err := h.BotSrv.GetTimezone(context.TODO(), update.Message.Chat.ID); err == nil {
+			tz = &chatTZ
 		}
 		text, err := h.BotSrv.RemindMe(update.Message.Chat.ID, update.Message.Text, tz)
 		response := telego.SendMessageParams{
 			ChatID: chatID,
 		}
-		if err != nil {
+		if errors.Is(err, service.ErrUnknownTimezone) {
+			response.Text = "Я не знаю вашего часового пояса. Ты можешь его добавить через /setlocation или /settz\n" +
+				"Или поставь напоминание без привязки к часам, например: /remindme через 30 минут проверить духовку"
+		} else if err != nil {
 			response.Text = "Упс, " + err.Error()
 		} else {
 			response.Text = text
@@ -114,6 +159,28 @@ func (h *Handler) InitRoutes() {
 
 	// }, th.CommandEqual("list"))
 
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Изменение напоминания
+		chatID := tu.ID(update.Message.Chat.ID)
+		var tz *models.ChatTimezone
+		if chatTZ, err := h.BotSrv.GetTimezone(context.TODO(), update.Message.Chat.ID); err == nil {
+			tz = &chatTZ
+		}
+		text, err := h.BotSrv.EditReminder(context.TODO(), update.Message.Chat.ID, update.Message.Text, tz)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if errors.Is(err, service.ErrUnknownTimezone) {
+			response.Text = "Я не знаю вашего часового пояса. Ты можешь его добавить через /setlocation или /settz\n" +
+				"Или укажи время без привязки к часам, например: /edit 3 через 30 минут"
+		} else if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("edit"))
+
 	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Удаление напоминания
 
 		text, err := h.BotSrv.DeleteReminder(context.TODO(), update.Message.Chat.ID, update.Message.Text)
@@ -130,6 +197,38 @@ func (h *Handler) InitRoutes() {
 
 	}, th.CommandEqual("del"))
 
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Теги
+
+		text, err := h.BotSrv.TagsCommand(context.TODO(), update.Message.Chat.ID)
+		chatID := tu.ID(update.Message.Chat.ID)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("tags"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Отложить напоминание или все напоминания с тегом
+
+		text, err := h.BotSrv.SnoozeCommand(context.TODO(), update.Message.Chat.ID, update.Message.Text)
+		chatID := tu.ID(update.Message.Chat.ID)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("snooze"))
+
 	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Помощь
 
 		text, err := h.BotSrv.HelpCommand()
@@ -151,75 +250,407 @@ func (h *Handler) InitRoutes() {
 		requestLocation(bot, chatID)
 	}, th.CommandEqual("setlocation"))
 
-	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) {
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Установить часовой пояс вручную
 		chatID := tu.ID(update.Message.Chat.ID)
-		ctx := context.TODO()
-		h.BotSrv.SetUserPage(ctx, update.Message.Chat.ID, 0)
-		buttons := createPaginationButtons()
-		text, err := h.GetListByPage(update.Message.Chat.ID, 0)
+		if strings.TrimSpace(strings.TrimPrefix(update.Message.Text, "/settz")) == "" {
+			bot.SendMessage(tu.Message(chatID, "Выбери регион:").WithReplyMarkup(createRegionButtons()))
+			return
+		}
+		oldZone := h.currentZone(update.Message.Chat.ID)
+		text, err := h.BotSrv.SetTimezoneByName(context.TODO(), update.Message.Chat.ID, update.Message.Text)
 		if err != nil {
-			log.Printf("\t Не получилось получить список напоминаний, ошибка: %v \n", err)
-			text = "Упс, какие то неполадки. Попробуй позже"
+			text = "Упс, " + err.Error()
 		}
-		msg := tu.Message(chatID, text).
-			WithReplyMarkup(buttons)
+		bot.SendMessage(tu.Message(chatID, text))
+		h.askReanchor(bot, update.Message.Chat.ID, oldZone)
+	}, th.CommandEqual("settz"))
 
-		bot.SendMessage(msg)
-	}, th.CommandEqual("list"))
-	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) {
-		// txt := update.EditedMessage
-		// fmt.Println(txt)
-		callbackData := update.CallbackQuery.Data
-		chat := update.CallbackQuery.Message
-		var pageUpdate int
-		var text string
-		var err error
-		if callbackData == "next" {
-			pageUpdate = 1
-			text, err = h.GetListByPage(update.CallbackQuery.Message.GetChat().ID, pageUpdate)
-			if err != nil {
-				log.Printf("\t Не получилось получить список напоминаний, ошибка: %v \n", err)
-				text = "Упс, какие то неполадки. Попробуй позже"
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Выбор часового пояса кнопками
+		query := update.CallbackQuery
+		chat := query.Message
+		params := &telego.EditMessageTextParams{
+			ChatID:    tu.ID(chat.GetChat().ID),
+			MessageID: chat.GetMessageID(),
+		}
+		switch {
+		case query.Data == "tzregions":
+			params.Text = "Выбери регион:"
+			params.ReplyMarkup = createRegionButtons()
+		case strings.HasPrefix(query.Data, "tzregion:"):
+			i, err := strconv.Atoi(strings.TrimPrefix(query.Data, "tzregion:"))
+			if err != nil || i < 0 || i >= len(tzresolver.Regions) {
+				return
 			}
-		} else if callbackData == "refresh" {
-			pageUpdate = 0
-			text, err = h.GetListByPage(update.CallbackQuery.Message.GetChat().ID, pageUpdate)
+			params.Text = tzresolver.Regions[i].Name + ": выбери город"
+			params.ReplyMarkup = createCityButtons(tzresolver.Regions[i])
+		case strings.HasPrefix(query.Data, "tzset:"):
+			oldZone := h.currentZone(chat.GetChat().ID)
+			text, err := h.BotSrv.SetTimezoneByName(context.TODO(), chat.GetChat().ID, strings.TrimPrefix(query.Data, "tzset:"))
 			if err != nil {
-				log.Printf("\t Не получилось получить список напоминаний, ошибка: %v \n", err)
-				text = "Упс, какие то неполадки. Попробуй позже"
+				text = "Упс, " + err.Error()
 			}
-		} else if callbackData == "back" {
-			pageUpdate = -1
-			text, err = h.GetListByPage(update.CallbackQuery.Message.GetChat().ID, pageUpdate)
-			if err != nil {
-				log.Printf("\t Не получилось получить список напоминаний, ошибка: %v \n", err)
-				text = "Упс, какие то неполадки. Попробуй позже"
+			params.Text = text
+			bot.EditMessageText(params)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+			h.askReanchor(bot, chat.GetChat().ID, oldZone)
+			return
+		}
+		bot.EditMessageText(params)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.Or(
+		th.CallbackDataEqual("tzregions"),
+		th.CallbackDataPrefix("tzregion:"),
+		th.CallbackDataPrefix("tzset:"),
+	))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Отложить отправленное напоминание
+		query := update.CallbackQuery
+		chat := query.Message
+		parts := strings.Split(query.Data, ":")
+		if len(parts) != 3 {
+			return
+		}
+		id, option := parts[1], parts[2]
+		if option == "pick" {
+			prompt := tu.Message(tu.ID(chat.GetChat().ID),
+				"Когда напомнить еще раз? Ответь на это сообщение, например: через 2 часа, завтра в 18:00 или 2024-10-10 12:00\nID: "+id).
+				WithReplyMarkup(tu.ForceReply().WithInputFieldPlaceholder("через 2 часа")).
+				WithReplyParameters(&telego.ReplyParameters{MessageID: chat.GetMessageID()})
+			bot.SendMessage(prompt)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+			return
+		}
+		text, err := h.BotSrv.SnoozeReminder(context.TODO(), chat.GetChat().ID, id, option)
+		if err != nil {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Упс, " + err.Error()))
+			return
+		}
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chat.GetChat().ID),
+			MessageID: chat.GetMessageID(),
+			Text:      text,
+		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.CallbackDataPrefix("snooze:"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Подтверждение напоминания
+		query := update.CallbackQuery
+		chat := query.Message
+		text, err := h.BotSrv.AcknowledgeReminder(context.TODO(), chat.GetChat().ID, strings.TrimPrefix(query.Data, "ack:"))
+		if err != nil {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Упс, " + err.Error()))
+			return
+		}
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chat.GetChat().ID),
+			MessageID: chat.GetMessageID(),
+			Text:      text,
+		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.CallbackDataPrefix("ack:"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Ответ со временем, на которое отложить напоминание
+		chatID := tu.ID(update.Message.Chat.ID)
+		id := snoozePromptID.FindStringSubmatch(update.Message.ReplyToMessage.Text)[1]
+		text, err := h.BotSrv.SnoozeReminderAt(context.TODO(), update.Message.Chat.ID, id, update.Message.Text)
+		if err != nil {
+			text = "Упс, " + err.Error()
+		}
+		bot.SendMessage(tu.Message(chatID, text))
+	}, func(update telego.Update) bool {
+		if update.Message == nil || update.Message.ReplyToMessage == nil || update.Message.ReplyToMessage.From == nil {
+			return false
+		}
+		reply := update.Message.ReplyToMessage
+		return reply.From.IsBot && snoozePromptID.MatchString(reply.Text)
+	})
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Утренняя сводка
+
+		text, err := h.BotSrv.DigestCommand(context.TODO(), update.Message.Chat.ID, update.Message.Text)
+		chatID := tu.ID(update.Message.Chat.ID)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("digest"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Тихие часы
+
+		text, err := h.BotSrv.QuietCommand(context.TODO(), update.Message.Chat.ID, update.Message.Text)
+		chatID := tu.ID(update.Message.Chat.ID)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("quiet"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Недоставленные напоминания, только для администратора
+
+		text, err := h.BotSrv.FailedReport(context.TODO(), update.Message.Chat.ID)
+		chatID := tu.ID(update.Message.Chat.ID)
+		response := telego.SendMessageParams{
+			ChatID: chatID,
+		}
+		if err != nil {
+			response.Text = "Упс, " + err.Error()
+		} else {
+			response.Text = text
+		}
+		bot.SendMessage(&response)
+
+	}, th.CommandEqual("failed"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Кнопки сводки: весь список и перенос дня
+		query := update.CallbackQuery
+		chat := query.Message
+		chatID := chat.GetChat().ID
+		if query.Data == "dg:list" {
+			text, buttons := h.listPage(chatID, listState{})
+			msg := tu.Message(tu.ID(chatID), text)
+			if buttons != nil {
+				msg = msg.WithReplyMarkup(buttons)
 			}
+			bot.SendMessage(msg)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+			return
+		}
+		text, err := h.BotSrv.PostponeDay(context.TODO(), chatID, strings.TrimPrefix(query.Data, "dg:postpone:"))
+		if err != nil {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Упс, " + err.Error()))
+			return
+		}
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: chat.GetMessageID(),
+			Text:      messageText(chat) + "\n\n" + text,
+		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.CallbackDataPrefix("dg:"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Пересчет напоминаний после смены часового пояса
+		query := update.CallbackQuery
+		chat := query.Message
+		text, err := h.BotSrv.ReanchorReminders(context.TODO(), chat.GetChat().ID, query.Data == "reanchor:wall")
+		if err != nil {
+			text = "Упс, " + err.Error()
+		}
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:    tu.ID(chat.GetChat().ID),
+			MessageID: chat.GetMessageID(),
+			Text:      text,
+		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+	}, th.Or(
+		th.CallbackDataEqual("reanchor:wall"),
+		th.CallbackDataEqual("reanchor:instant"),
+	))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Список напоминаний, в том числе с фильтром или поиском
+		chatID := tu.ID(update.Message.Chat.ID)
+		text, buttons := h.listPage(update.Message.Chat.ID, listState{Filter: update.Message.Text})
+		msg := tu.Message(chatID, text)
+		if buttons != nil {
+			msg = msg.WithReplyMarkup(buttons)
 		}
-		buttons := createPaginationButtons()
+
+		bot.SendMessage(msg)
+	}, th.Or(th.CommandEqual("list"), th.CommandEqual("find")))
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Переключение страниц списка
+		query := update.CallbackQuery
+		chat := query.Message
+		// Кнопки "back", "refresh" и "next" остались под списками, отправленными до появления номеров страниц
+		state, ok := parseListState(strings.TrimPrefix(query.Data, "lp:"), service.ListFilterFromText(messageText(chat)))
+		if strings.HasPrefix(query.Data, "lp:") && !ok {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Этот список устарел, открой /list заново"))
+			return
+		}
+		text, buttons := h.listPage(chat.GetChat().ID, state)
 		bot.EditMessageText(&telego.EditMessageTextParams{
 			ChatID:      tu.ID(chat.GetChat().ID),
 			MessageID:   chat.GetMessageID(),
 			Text:        text,
 			ReplyMarkup: buttons,
 		})
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
 	}, th.Or(
+		th.CallbackDataPrefix("lp:"),
 		th.CallbackDataEqual("back"),
 		th.CallbackDataEqual("refresh"),
 		th.CallbackDataEqual("next"),
 	))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Кнопки у напоминаний в списке
+		query := update.CallbackQuery
+		chat := query.Message
+		chatID := chat.GetChat().ID
+		// li:<действие>:<номер напоминания>:<страница>:<отпечаток фильтра>
+		parts := strings.SplitN(query.Data, ":", 4)
+		if len(parts) != 4 {
+			return
+		}
+		action := parts[1]
+		num, err := strconv.Atoi(parts[2])
+		state, ok := parseListState(parts[3], service.ListFilterFromText(messageText(chat)))
+		if err != nil || !ok {
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText("Этот список устарел, открой /list заново"))
+			return
+		}
+		edit := &telego.EditMessageTextParams{
+			ChatID:    tu.ID(chatID),
+			MessageID: chat.GetMessageID(),
+		}
+		var notice string
+		switch action {
+		case "info", "edit":
+			text, err := h.BotSrv.ReminderDetails(context.TODO(), chatID, num)
+			if err != nil {
+				notice = "Упс, " + err.Error()
+				break
+			}
+			if state.Filter != "" {
+				// Фильтр остается в тексте, чтобы кнопка "К списку" вернула к тому же списку
+				text += "\n\n" + service.ListFilterMark + state.Filter
+			}
+			edit.Text = text
+			edit.ReplyMarkup = createItemButtons(num, state)
+			if action == "edit" {
+				edit.Text = fmt.Sprintf("✏️ Изменение напоминания №%d\n\n%s\n\n"+
+					"Ответь на это сообщение новым временем и/или текстом, как в /edit: например «завтра в 10:00» или «купить хлеб»", num, text)
+				edit.ReplyMarkup = tu.InlineKeyboard(tu.InlineKeyboardRow(
+					tu.InlineKeyboardButton("← К списку").WithCallbackData(state.pageCallback(state.Page))))
+			}
+			bot.EditMessageText(edit)
+			bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID))
+			return
+		case "del":
+			notice, err = h.BotSrv.DeleteReminderByNum(context.TODO(), chatID, num)
+		case "snooze":
+			notice, err = h.BotSrv.PostponeReminder(context.TODO(), chatID, num)
+		}
+		if err != nil {
+			notice = "Упс, " + err.Error()
+		}
+		edit.Text, edit.ReplyMarkup = h.listPage(chatID, state)
+		bot.EditMessageText(edit)
+		bot.AnswerCallbackQuery(tu.CallbackQuery(query.ID).WithText(notice))
+	}, th.CallbackDataPrefix("li:"))
+
+	h.BotHandler.Handle(func(bot *telego.Bot, update telego.Update) { // Ответ с изменениями для напоминания из списка
+		chatID := update.Message.Chat.ID
+		prompt := update.Message.ReplyToMessage
+		num := editPromptNum.FindStringSubmatch(prompt.Text)[1]
+		var tz *models.ChatTimezone
+		if chatTZ, err := h.BotSrv.GetTimezone(context.TODO(), chatID); err == nil {
+			tz = &chatTZ
+		}
+		text, err := h.BotSrv.EditReminder(context.TODO(), chatID, "/edit "+num+" "+update.Message.Text, tz)
+		if errors.Is(err, service.ErrUnknownTimezone) {
+			text = "Я не знаю вашего часового пояса. Ты можешь его добавить через /setlocation или /settz\n" +
+				"Или укажи время без привязки к часам, например: через 30 минут"
+		} else if err != nil {
+			text = "Упс, " + err.Error()
+		}
+		bot.SendMessage(tu.Message(tu.ID(chatID), text))
+		if err != nil {
+			return
+		}
+		listText, buttons := h.listPage(chatID, promptListState(prompt))
+		bot.EditMessageText(&telego.EditMessageTextParams{
+			ChatID:      tu.ID(chatID),
+			MessageID:   prompt.MessageID,
+			Text:        listText,
+			ReplyMarkup: buttons,
+		})
+	}, func(update telego.Update) bool {
+		if update.Message == nil || update.Message.ReplyToMessage == nil || update.Message.ReplyToMessage.From == nil {
+			return false
+		}
+		reply := update.Message.ReplyToMessage
+		return reply.From.IsBot && editPromptNum.MatchString(reply.Text)
+	})
 }
 
-func createPaginationButtons() *telego.InlineKeyboardMarkup {
+// currentZone возвращает часовой пояс чата до его смены, чтобы потом спросить про пересчет напоминаний.
+func (h *Handler) currentZone(chatID int64) string {
+	tz, err := h.BotSrv.GetTimezone(context.TODO(), chatID)
+	if err != nil {
+		return ""
+	}
+	return tz.Zone
+}
 
-	inlineKeyboard := tu.InlineKeyboard(
-		tu.InlineKeyboardRow( // Row 1
-			tu.InlineKeyboardButton("Назад").WithCallbackData("back"),
-			tu.InlineKeyboardButton("Обновить").WithCallbackData("refresh"),
-			tu.InlineKeyboardButton("Вперед").WithCallbackData("next"),
-		),
+// askReanchor спрашивает, как пересчитать напоминания, если часовой пояс сменился.
+func (h *Handler) askReanchor(bot *telego.Bot, chatID int64, oldZone string) {
+	if !h.BotSrv.NeedsReanchor(context.TODO(), chatID, oldZone) {
+		return
+	}
+	buttons := tu.InlineKeyboard(
+		tu.InlineKeyboardRow(tu.InlineKeyboardButton("То же время на часах").WithCallbackData("reanchor:wall")),
+		tu.InlineKeyboardRow(tu.InlineKeyboardButton("Тот же момент").WithCallbackData("reanchor:instant")),
 	)
-	return inlineKeyboard
+	bot.SendMessage(tu.Message(tu.ID(chatID),
+		"Часовой пояс изменился. Как быть с уже поставленными напоминаниями?\n"+
+			"То же время на часах - напоминание на 9:00 придет в 9:00 по новому местному времени.\n"+
+			"Тот же момент - напоминание придет тогда же, когда пришло бы и раньше.").WithReplyMarkup(buttons))
+}
+
+// createReminderButtons - кнопки под отправленным напоминанием. "Готово" есть только у напоминаний с !nag
+// и не нужна под предупреждением заранее.
+func createReminderButtons(reminder models.Reminder, alert bool) *telego.InlineKeyboardMarkup {
+	id := reminder.ID
+	var rows [][]telego.InlineKeyboardButton
+	if reminder.Nag != nil && !alert {
+		rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton("✅ Готово").WithCallbackData("ack:"+id)))
+	}
+	return tu.InlineKeyboard(append(rows,
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("+10 мин").WithCallbackData("snooze:"+id+":"+service.SnoozeTenMinutes),
+			tu.InlineKeyboardButton("+1 час").WithCallbackData("snooze:"+id+":"+service.SnoozeHour),
+			tu.InlineKeyboardButton("Завтра").WithCallbackData("snooze:"+id+":"+service.SnoozeTomorrow),
+		),
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("Выбрать время").WithCallbackData("snooze:"+id+":pick"),
+		),
+	)...)
+}
+
+func createRegionButtons() *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for i := 0; i < len(tzresolver.Regions); i += 2 {
+		var row []telego.InlineKeyboardButton
+		for j := i; j < i+2 && j < len(tzresolver.Regions); j++ {
+			row = append(row, tu.InlineKeyboardButton(tzresolver.Regions[j].Name).WithCallbackData("tzregion:"+strconv.Itoa(j)))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton("UTC").WithCallbackData("tzset:UTC")))
+	return tu.InlineKeyboard(rows...)
+}
+
+func createCityButtons(region tzresolver.Region) *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for i := 0; i < len(region.Cities); i += 3 {
+		var row []telego.InlineKeyboardButton
+		for j := i; j < i+3 && j < len(region.Cities); j++ {
+			row = append(row, tu.InlineKeyboardButton(region.Cities[j].Name).WithCallbackData("tzset:"+region.Cities[j].Zone))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, tu.InlineKeyboardRow(tu.InlineKeyboardButton("Назад").WithCallbackData("tzregions")))
+	return tu.InlineKeyboard(rows...)
 }
 
 func requestLocation(bot *telego.Bot, chatID telego.ChatID) {
@@ -236,7 +667,8 @@ func requestLocation(bot *telego.Bot, chatID telego.ChatID) {
 	}
 	response := tu.Message(
 		chatID,
-		"Для работы мне нужен твой часовой пояс, разреши мне узнать твою геолокацию. Ты всегда можешь удалить эту информацию путем команды /deletelocation",
+		"Для работы мне нужен твой часовой пояс, разреши мне узнать твою геолокацию. Ты всегда можешь удалить эту информацию путем команды /deletelocation\n"+
+			"Если не хочешь делиться геолокацией, укажи часовой пояс или город через /settz",
 	).WithReplyMarkup(&replyMarkup)
 
 	bot.SendMessage(response)
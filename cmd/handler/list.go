@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"JillBot/internal/models"
+	"JillBot/internal/service"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// listState - какую страницу какого списка показывает сообщение. Оно целиком хранится в callback data
+// кнопок под сообщением, поэтому несколько открытых списков не мешают друг другу.
+type listState struct {
+	Page   int
+	Filter string
+}
+
+// listFingerprint - короткий отпечаток фильтра списка для callback data, размер которой ограничен 64 байтами.
+func listFingerprint(filter string) string {
+	h := fnv.New32a()
+	h.Write([]byte(filter))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// parseListState разбирает "<страница>:<отпечаток фильтра>" из callback data. Сам фильтр берется из текста
+// сообщения со списком, а отпечаток подтверждает, что это тот же фильтр. Если они не совпадают,
+// кнопка осталась от списка, который уже не восстановить.
+func parseListState(data, filter string) (listState, bool) {
+	page, fingerprint, found := strings.Cut(data, ":")
+	if !found {
+		return listState{}, false
+	}
+	n, err := strconv.Atoi(page)
+	if err != nil || n < 0 {
+		return listState{}, false
+	}
+	state := listState{Page: n, Filter: filter}
+	return state, fingerprint == listFingerprint(filter)
+}
+
+func (s listState) pageCallback(page int) string {
+	return fmt.Sprintf("lp:%d:%s", max(page, 0), listFingerprint(s.Filter))
+}
+
+func (s listState) itemCallback(action string, num int) string {
+	return fmt.Sprintf("li:%s:%d:%d:%s", action, num, s.Page, listFingerprint(s.Filter))
+}
+
+// promptListState достает страницу списка из кнопки "К списку" под сообщением "Изменение напоминания".
+func promptListState(prompt *telego.Message) listState {
+	if prompt.ReplyMarkup == nil {
+		return listState{}
+	}
+	for _, row := range prompt.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			data := strings.TrimPrefix(button.CallbackData, "lp:")
+			if state, ok := parseListState(data, service.ListFilterFromText(prompt.Text)); ok {
+				return state
+			}
+		}
+	}
+	return listState{}
+}
+
+// messageText возвращает текст сообщения, под которым нажата кнопка.
+func messageText(message telego.MaybeInaccessibleMessage) string {
+	if msg, ok := message.(*telego.Message); ok {
+		return msg.Text
+	}
+	return ""
+}
+
+// listPage возвращает страницу списка и кнопки к ней. Если запрошенной страницы уже нет, показывается последняя.
+// Для неправильного фильтра кнопок нет.
+func (h *Handler) listPage(chatID int64, state listState) (string, *telego.InlineKeyboardMarkup) {
+	list, err := h.GetListByPage(chatID, state.Page, state.Filter)
+	if errors.Is(err, service.ErrBadFilter) {
+		return "Упс, " + err.Error(), nil
+	}
+	if err != nil {
+		log.Printf("\t Не получилось получить список напоминаний, ошибка: %v \n", err)
+		return "Упс, какие то неполадки. Попробуй позже", createPaginationButtons(state, 1)
+	}
+	state.Page, state.Filter = list.Page, list.Filter
+	return list.Text, createListButtons(list.Reminders, state, list.Pages)
+}
+
+// createListButtons - кнопки под каждым напоминанием страницы и переключение страниц.
+// В callback data номер напоминания: он короткий и однозначен в пределах чата.
+func createListButtons(reminders []models.Reminder, state listState, pages int) *telego.InlineKeyboardMarkup {
+	var rows [][]telego.InlineKeyboardButton
+	for _, reminder := range reminders {
+		rows = append(rows, tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton(fmt.Sprintf("№%d ℹ️", reminder.Num)).WithCallbackData(state.itemCallback("info", reminder.Num)),
+			tu.InlineKeyboardButton("🗑").WithCallbackData(state.itemCallback("del", reminder.Num)),
+			tu.InlineKeyboardButton("⏰ +1 ч").WithCallbackData(state.itemCallback("snooze", reminder.Num)),
+			tu.InlineKeyboardButton("✏️").WithCallbackData(state.itemCallback("edit", reminder.Num)),
+		))
+	}
+	return tu.InlineKeyboard(append(rows, createPaginationButtons(state, pages).InlineKeyboard...)...)
+}
+
+// createItemButtons - кнопки под подробностями одного напоминания.
+func createItemButtons(num int, state listState) *telego.InlineKeyboardMarkup {
+	return tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("🗑 Удалить").WithCallbackData(state.itemCallback("del", num)),
+			tu.InlineKeyboardButton("⏰ +1 ч").WithCallbackData(state.itemCallback("snooze", num)),
+			tu.InlineKeyboardButton("✏️ Изменить").WithCallbackData(state.itemCallback("edit", num)),
+		),
+		tu.InlineKeyboardRow(tu.InlineKeyboardButton("← К списку").WithCallbackData(state.pageCallback(state.Page))),
+	)
+}
+
+func createPaginationButtons(state listState, pages int) *telego.InlineKeyboardMarkup {
+
+	inlineKeyboard := tu.InlineKeyboard(
+		tu.InlineKeyboardRow( // Row 1
+			tu.InlineKeyboardButton("Назад").WithCallbackData(state.pageCallback(state.Page-1)),
+			tu.InlineKeyboardButton("Обновить").WithCallbackData(state.pageCallback(state.Page)),
+			tu.InlineKeyboardButton("Вперед").WithCallbackData(state.pageCallback(min(state.Page+1, pages-1))),
+		),
+	)
+	return inlineKeyboard
+}
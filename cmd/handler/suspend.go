@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
+	tu "github.com/mymmrac/telego/telegoutil"
+)
+
+// offerResume предлагает вернувшемуся чату возобновить напоминания, если они стояли на паузе.
+func (h *Handler) offerResume(bot *telego.Bot, chatID int64) {
+	text, ok, err := h.BotSrv.ResumeOffer(context.TODO(), chatID)
+	if err != nil {
+		log.Printf("Ошибка при проверке паузы чата: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	bot.SendMessage(tu.Message(tu.ID(chatID), text).WithReplyMarkup(createResumeButtons()))
+}
+
+func createResumeButtons() *telego.InlineKeyboardMarkup {
+	return tu.InlineKeyboard(
+		tu.InlineKeyboardRow(
+			tu.InlineKeyboardButton("▶️ Возобновить").WithCallbackData("rs:resume"),
+			tu.InlineKeyboardButton("Не сейчас").WithCallbackData("rs:later"),
+		),
+	)
+}
+
+// suspendIfForbidden ставит напоминания чата на паузу, если Telegram ответил 403: бота заблокировали
+// или удалили из группы. true - чат недоступен, и повторять отправку бессмысленно.
+func (h *Handler) suspendIfForbidden(chatID int64, err error) bool {
+	var apiErr *ta.Error
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode != 403 {
+		return false
+	}
+	h.suspendChat(chatID)
+	return true
+}
+
+// suspendChat ставит на паузу напоминания чата, в который бот больше не может писать.
+func (h *Handler) suspendChat(chatID int64) {
+	if err := h.BotSrv.SuspendChat(context.TODO(), chatID); err != nil {
+		log.Printf("Ошибка при постановке чата на паузу: %v", err)
+	}
+}
+
+// chatLeft - бот больше не может писать в чат с таким статусом: его заблокировали, удалили или он вышел сам.
+func chatLeft(status string) bool {
+	return status == telego.MemberStatusBanned || status == telego.MemberStatusLeft
+}
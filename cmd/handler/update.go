@@ -1,46 +1,164 @@
 package handler
 
 import (
+	"JillBot/internal/models"
+	"JillBot/pkg/scheduler"
+	"JillBot/pkg/sender"
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/mymmrac/telego"
+	ta "github.com/mymmrac/telego/telegoapi"
 	tu "github.com/mymmrac/telego/telegoutil"
 )
 
-func (h *Handler) StartCheckingReminders(bot *telego.Bot) {
+// maxQueued - сколько сообщений может ждать в очереди отправки, прежде чем бот перестанет захватывать
+// новые напоминания. Это минута работы при общем лимите 30 сообщений в секунду. Очередь одного чата
+// идет медленнее, по сообщению в секунду, поэтому там, где ожидание не укладывается в аренду,
+// она продлевается перед постановкой в очередь (ExtendLease).
+const maxQueued = 1800
 
+// StartCheckingReminders доставляет напоминания в срок с точностью до секунды: планировщик спит до
+// ближайшего срабатывания и раз в Refill сверяется с базой. Сводки проверяются отдельно, раз в минуту.
+// Напоминания и сводки уходят через очередь out, которая держит темп в пределах ограничений Telegram.
+//
+// После отмены ctx возвращается, когда очередь остановится и вернет в базу напоминания, которые
+// не успела отправить.
+func (h *Handler) StartCheckingReminders(ctx context.Context, bot *telego.Bot, sch *scheduler.Scheduler, out *sender.Sender) {
+	stopped := make(chan struct{})
+	go func() {
+		out.Run(ctx)
+		close(stopped)
+	}()
+	go h.startDigests(ctx, bot, out)
+	sch.Run(ctx, h.upcomingTimes, func(ctx context.Context) {
+		if !h.deliverDue(ctx, bot, out) {
+			// Очередь переполнена - вернуться к оставшимся напоминаниям, когда она немного разойдется
+			sch.Schedule(time.Now().Add(time.Second))
+		}
+	})
+	<-stopped
+}
+
+// upcomingTimes - сроки срабатывания напоминаний до until для планировщика.
+func (h *Handler) upcomingTimes(ctx context.Context, until time.Time) ([]time.Time, error) {
+	reminders, err := h.BotSrv.GetUpcomingReminders(ctx, until)
+	if err != nil {
+		return nil, err
+	}
+	times := make([]time.Time, len(reminders))
+	for i, reminder := range reminders {
+		times[i] = reminder.Time
+		// Напоминание отправляет другая реплика - проверить, справилась ли она, стоит после конца аренды
+		if reminder.LeaseUntil != nil && reminder.LeaseUntil.After(reminder.Time) {
+			times[i] = *reminder.LeaseUntil
+		}
+	}
+	return times, nil
+}
+
+// deliverDue ставит в очередь отправки все напоминания, время которых наступило. Каждое напоминание
+// сначала захватывается, поэтому при нескольких запущенных репликах бота его отправит только одна из них.
+// false - очередь переполнена и наступившие напоминания еще остались.
+func (h *Handler) deliverDue(ctx context.Context, bot *telego.Bot, out *sender.Sender) bool {
 	for {
-		reminders, err := h.BotSrv.GetUpcomingReminders(context.TODO())
+		if out.Len() >= maxQueued {
+			return false
+		}
+		reminder, ok, err := h.BotSrv.ClaimDueReminder(ctx)
 		if err != nil {
 			log.Printf("Ошибка при получении напоминаний: %v", err)
+			return true
+		}
+		if !ok {
+			return true
+		}
+		silent, deferred, err := h.BotSrv.CheckQuietHours(ctx, reminder)
+		if err != nil {
+			log.Printf("Ошибка при проверке тихих часов: %v", err)
+		}
+		if deferred {
 			continue
 		}
-		time.Sleep(5 * time.Second)
-
-		for _, reminder := range reminders {
-			response := telego.SendMessageParams{
-				ChatID: tu.ID(reminder.ChatID),
-				Text:   reminder.Action,
-			}
-			_, err := bot.SendMessage(&response)
-			if err != nil {
-				log.Printf("Ошибка отправки сообщения: %v", err)
-				continue
-			}
-			err = h.BotSrv.MarkReminderAsSent(context.TODO(), reminder.ChatID, reminder.ID)
+		// Иначе аренда может истечь в очереди, и напоминание захватят и отправят второй раз
+		held, err := h.BotSrv.ExtendLease(ctx, reminder, out.Wait(reminder.ChatID))
+		if err != nil {
+			log.Printf("Ошибка при продлении аренды напоминания: %v", err)
+		} else if !held {
+			continue
+		}
+		text, alert := h.BotSrv.DeliveryText(reminder)
+		response := telego.SendMessageParams{
+			ChatID:              tu.ID(reminder.ChatID),
+			Text:                text,
+			ReplyMarkup:         createReminderButtons(reminder, alert),
+			DisableNotification: silent,
+		}
+		out.Enqueue(reminder.ChatID, func() error {
+			// Очередь чата может идти минутами - за это время напоминание могли подтвердить, удалить или перехватить
+			due, err := h.BotSrv.StillDue(ctx, reminder)
 			if err != nil {
-				log.Printf("Ошибка при обновлении статуса напоминания: %v", err)
+				log.Printf("Ошибка при проверке напоминания перед отправкой: %v", err)
+			} else if !due {
+				return sender.ErrSkipped
 			}
+			_, err = bot.SendMessage(&response)
+			return err
+		}, func(err error) {
+			// Результат записывается и после остановки бота, иначе отправленное напоминание уйдет еще раз
+			h.reminderSent(context.WithoutCancel(ctx), reminder, err)
+		})
+	}
+}
+
+// reminderSent записывает результат отправки напоминания.
+func (h *Handler) reminderSent(ctx context.Context, reminder models.Reminder, err error) {
+	if errors.Is(err, sender.ErrSkipped) {
+		return
+	}
+	if errors.Is(err, sender.ErrStopped) {
+		// Бот останавливается - отдать напоминание другим репликам, не дожидаясь конца аренды
+		if err := h.BotSrv.ReleaseReminder(ctx, reminder); err != nil {
+			log.Printf("Ошибка при возврате напоминания в очередь: %v", err)
 		}
-		setTimer()
+		return
 	}
+	if err != nil {
+		log.Printf("Ошибка отправки сообщения: %v", err)
+		if h.suspendIfForbidden(reminder.ChatID, err) {
+			return
+		}
+		if err := h.BotSrv.DeliveryFailed(ctx, reminder, err.Error(), retryAfter(err)); err != nil {
+			log.Printf("Ошибка при сохранении неудачной отправки: %v", err)
+		}
+		return
+	}
+	if err := h.BotSrv.MarkReminderAsSent(ctx, reminder); err != nil {
+		log.Printf("Ошибка при обновлении статуса напоминания: %v", err)
+	}
+}
+
+// retryAfter - сколько Telegram просит подождать перед следующей отправкой, если он ответил 429.
+func retryAfter(err error) time.Duration {
+	var apiErr *ta.Error
+	if errors.As(err, &apiErr) && apiErr.ErrorCode == 429 && apiErr.Parameters != nil {
+		return time.Duration(apiErr.Parameters.RetryAfter) * time.Second
+	}
+	return 0
 }
 
-func setTimer() {
-	now := time.Now()
-	secondsUntilNextMinute := 60 - now.Second()
-	waitTime := time.Duration(secondsUntilNextMinute-5) * time.Second
-	time.Sleep(waitTime)
+// startDigests раз в минуту отправляет сводки, время которых подошло.
+func (h *Handler) startDigests(ctx context.Context, bot *telego.Bot, out *sender.Sender) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		h.sendDigests(bot, out)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -6,11 +6,20 @@ import (
 	"JillBot/internal/service"
 	"JillBot/internal/storage"
 	"JillBot/pkg/ipgeolocation"
+	"JillBot/pkg/scheduler"
+	"JillBot/pkg/sender"
+	"JillBot/pkg/tzresolver"
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/mymmrac/telego"
 	th "github.com/mymmrac/telego/telegohandler"
@@ -40,12 +49,82 @@ func main() {
 	defer mongodb.Disconnect(ctx)
 	defer bh.Stop()
 	defer bot.StopLongPolling()
-	collections := []string{"reminders","timezones", "pagestate"}
-	store := storage.NewRemindersStorage(mongodb, "remindersdb", collections)
-	var timeDiffApi ipgeolocation.TimeDiffGetter
-	botSRV := service.NewBotService(store, timeDiffApi)
+	store := storage.NewRemindersStorage(mongodb, "remindersdb", storage.Collections{
+		Reminders: "reminders",
+		Timezones: "timezones",
+		Counters:  "counters",
+		Settings:  "settings",
+	})
+	if err := store.EnsureIndexes(ctx); err != nil {
+		log.Printf("Не удалось создать индексы: %v", err)
+	}
+	// Без ключа API часовой пояс определяется по координатам офлайн, по встроенным данным tzdb
+	var zoneGetter ipgeolocation.ZoneGetter
+	if apiKey := os.Getenv("TIMEZONE_API"); apiKey != "" {
+		zoneGetter = ipgeolocation.NewClient(apiKey)
+	} else {
+		zoneGetter, err = tzresolver.New()
+		if err != nil {
+			log.Fatalf("Failed to load time zone data: %s", err)
+		}
+	}
+	botSRV := service.NewBotService(store, zoneGetter)
+	migrated, err := botSRV.MigrateTimezones(context.Background())
+	if err != nil {
+		log.Printf("Не удалось перевести часовые пояса на IANA-зоны: %v", err)
+	} else if migrated > 0 {
+		log.Printf("Часовые пояса переведены на IANA-зоны: %d", migrated)
+	}
+	numbered, err := botSRV.MigrateReminderNumbers(context.Background())
+	if err != nil {
+		log.Printf("Не удалось пронумеровать напоминания: %v", err)
+	} else if numbered > 0 {
+		log.Printf("Пронумеровано старых напоминаний: %d", numbered)
+	}
+	botSRV.Admins = parseChatIDs(os.Getenv("ADMIN_CHAT_IDS"))
+	sch := scheduler.New()
+	botSRV.Scheduler = sch
+	// Метрики очереди отправки (expvar) доступны по http://METRICS_ADDR/debug/vars
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		go func() {
+			log.Printf("Сервер метрик остановлен: %v", http.ListenAndServe(addr, nil))
+		}()
+	}
 	h := handler.NewHandler(bh, botSRV)
 	h.InitRoutes()
-	go h.StartCheckingReminders(bot)
+	// SIGTERM (остановка контейнера) и Ctrl+C останавливают бота. Напоминания, которые он захватил,
+	// но не успел отправить, возвращаются в базу, и их сразу подхватывает другая реплика
+	runCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	checking := make(chan struct{})
+	go func() {
+		h.StartCheckingReminders(runCtx, bot, sch, sender.New())
+		close(checking)
+	}()
+	go func() {
+		<-runCtx.Done()
+		bh.Stop()
+	}()
 	bh.Start()
+	// Обработчик обновлений может остановиться и без сигнала - тогда остановить и доставку
+	stop()
+	<-checking
+}
+
+// parseChatIDs разбирает список ID чатов через запятую, например "123,-100456".
+func parseChatIDs(list string) []int64 {
+	var ids []int64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			log.Printf("Неверный ID чата в ADMIN_CHAT_IDS: %q", field)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
 }
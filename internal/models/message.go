@@ -9,27 +9,152 @@ type MessageParts struct {
 	Action string
 }
 type Reminder struct {
-	ID       string    `bson:"_id,omitempty"`
-	ChatID   int64     `bson:"chat_id"`
-	Action   string    `bson:"action"`
-	Time     time.Time `bson:"utc_time"`
-	OriginalTime time.Time `bson:"time"`
-	IsActive bool      `bson:"is_active"`
+	ID     string `bson:"_id,omitempty"`
+	ChatID int64  `bson:"chat_id"`
+	// Num - короткий номер напоминания, уникальный в пределах чата: /del 3, /edit 3.
+	Num          int         `bson:"num,omitempty"`
+	Action       string      `bson:"action"`
+	Time         time.Time   `bson:"utc_time"`
+	OriginalTime time.Time   `bson:"time"`
+	IsActive     bool        `bson:"is_active"`
+	Recurrence   *Recurrence `bson:"recurrence,omitempty"`
+	// Relative - напоминание задано через сколько сработать и не зависит от часов пользователя.
+	Relative bool `bson:"relative,omitempty"`
+	// Nag - повторять напоминание, пока пользователь не нажмет "Готово".
+	Nag *Nag `bson:"nag,omitempty"`
+	// NagCount - сколько раз напоминание уже повторено в текущем срабатывании.
+	NagCount int `bson:"nag_count,omitempty"`
+	// DeliveredAt - когда напоминание было отправлено в первый раз в текущем срабатывании.
+	DeliveredAt *time.Time `bson:"delivered_at,omitempty"`
+	// AcknowledgedAt - когда пользователь последний раз нажал "Готово".
+	AcknowledgedAt *time.Time `bson:"acknowledged_at,omitempty"`
+	// Urgent - срочное напоминание (флаг "!"), приходит и в тихие часы.
+	Urgent bool `bson:"urgent,omitempty"`
+	// DeferredFrom - когда напоминание должно было сработать, если тихие часы отложили его до своего конца.
+	DeferredFrom *time.Time `bson:"deferred_from,omitempty"`
+	// ClaimedBy и LeaseUntil - какая реплика бота отправляет напоминание и до какого момента. Пока аренда
+	// не истекла, другие реплики напоминание не трогают; аренда снимается, когда срабатывание обработано.
+	ClaimedBy  string     `bson:"claimed_by,omitempty"`
+	LeaseUntil *time.Time `bson:"lease_until,omitempty"`
+	// Attempts - сколько раз подряд не удалось отправить текущее срабатывание, LastError - последняя ошибка.
+	Attempts  int    `bson:"attempts,omitempty"`
+	LastError string `bson:"last_error,omitempty"`
+	// FailedAt - когда напоминание бросили отправлять после maxDeliveryAttempts неудач. Такое напоминание
+	// снято с активных и попадает в отчет администратора.
+	FailedAt *time.Time `bson:"failed_at,omitempty"`
+	// Suspended - чат недоступен (бот заблокирован или удален из группы), напоминание ждет возобновления
+	Suspended bool `bson:"suspended,omitempty"`
+	// Tags - теги из текста напоминания, без "#" и в нижнем регистре.
+	Tags []string `bson:"tags,omitempty"`
+	// Alerts - предупреждения заранее. Пока они не отправлены, utc_time указывает на ближайшее из них,
+	// а не на само событие.
+	Alerts []Alert `bson:"alerts,omitempty"`
+}
+
+// Alert - предупреждение за Lead минут до события.
+type Alert struct {
+	Lead int       `bson:"lead"`
+	At   time.Time `bson:"at"`
+	// SentAt - когда предупреждение было отправлено.
+	SentAt *time.Time `bson:"sent_at,omitempty"`
+	// Skipped - к моменту планирования время предупреждения уже прошло.
+	Skipped bool `bson:"skipped,omitempty"`
+}
+
+type Nag struct {
+	// Interval - через сколько минут повторить неподтвержденное напоминание.
+	Interval int `bson:"interval"`
+	// MaxRepeats - сколько раз повторить, прежде чем сдаться.
+	MaxRepeats int `bson:"max_repeats"`
+}
+
+type Recurrence struct {
+	Frequency string         `bson:"frequency"`
+	Weekdays  []time.Weekday `bson:"weekdays,omitempty"`
+	Day       int            `bson:"day,omitempty"`
 }
 
 type Command struct {
-    Command     string `json:"command"`
-    Description string `json:"description"`
+	Command     string `json:"command"`
+	Description string `json:"description"`
 }
 
-type ChatTimezone struct{
-	ChatID int64 `bson:"chat_id"`
-	Latitude float64 `bson:"lat"`
+type ChatTimezone struct {
+	ChatID    int64   `bson:"chat_id"`
+	Latitude  float64 `bson:"lat"`
 	Longitude float64 `bson:"long"`
-	Diff_hour int `bson:"diff_hour"`
+	Zone      string  `bson:"zone"`
+}
+
+// LegacyTimezone - запись часового пояса в старом формате, со сдвигом в целых часах.
+type LegacyTimezone struct {
+	ChatID    int64   `bson:"chat_id"`
+	Latitude  float64 `bson:"lat"`
+	Longitude float64 `bson:"long"`
+	DiffHour  int     `bson:"diff_hour"`
+}
+
+// ChatSettings - настройки чата. Документ появляется, когда чат что-то настроил.
+type ChatSettings struct {
+	ChatID int64           `bson:"chat_id"`
+	Digest *DigestSettings `bson:"digest,omitempty"`
+	Quiet  *QuietHours     `bson:"quiet,omitempty"`
+	// SuspendedAt - когда бота заблокировали или удалили из чата; nil, если чат доступен
+	SuspendedAt *time.Time `bson:"suspended_at,omitempty"`
 }
 
-type UserPageState struct {
-    ChatID int64 `bson:"chat_id"`
-    Page   int   `bson:"page"`
-}
\ No newline at end of file
+// QuietHours - тихие часы чата по местному времени, например с 23:00 до 07:30.
+type QuietHours struct {
+	Enabled bool `bson:"enabled"`
+	// Start и End - начало и конец в минутах от полуночи. Если Start больше End, окно переходит через полночь.
+	Start int `bson:"start"`
+	End   int `bson:"end"`
+	// Silent - присылать напоминания в тихие часы без звука, а не откладывать до конца окна.
+	Silent bool `bson:"silent,omitempty"`
+}
+
+// DigestSettings - утренняя сводка напоминаний на день.
+type DigestSettings struct {
+	Enabled bool `bson:"enabled"`
+	// Minute - во сколько присылать сводку, в минутах от полуночи по местному времени чата.
+	Minute int `bson:"minute"`
+	// Next - когда прислать следующую сводку (UTC).
+	Next time.Time `bson:"next"`
+}
+
+// Digest - готовая сводка для отправки. Day - день сводки по местному времени, "2006-01-02".
+type Digest struct {
+	ChatID int64
+	Day    string
+	Text   string
+}
+
+// ReminderFilter - условия выборки для списка напоминаний. Пустые поля выборку не ограничивают.
+type ReminderFilter struct {
+	// From и To - границы по местному времени события (поле time), To не включается.
+	From time.Time
+	To   time.Time
+	// DueBefore - напоминание должно было сработать раньше этого момента (поле utc_time).
+	DueBefore time.Time
+	// Query - подстрока в тексте действия, без учета регистра.
+	Query string
+	// Tag - у напоминания есть этот тег.
+	Tag string
+}
+
+// TagCount - сколько активных напоминаний чата с тегом.
+type TagCount struct {
+	Tag   string `bson:"_id"`
+	Count int    `bson:"count"`
+}
+
+// ListPage - страница списка напоминаний.
+type ListPage struct {
+	Text      string
+	Reminders []Reminder
+	// Page - номер показанной страницы с нуля. Если запрошенной страницы уже нет, показывается последняя.
+	Page  int
+	Pages int
+	// Filter - команда, которой задан фильтр списка, например "/list today" или "/find врач"; пустая для всего списка.
+	Filter string
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// newAlerts строит предупреждения за leads минут до события event. Предупреждения, время которых
+// к now уже прошло, остаются в списке пропущенными: у повторяющихся напоминаний они пригодятся в следующий раз.
+func newAlerts(leads []int, event, now time.Time) []models.Alert {
+	if len(leads) == 0 {
+		return nil
+	}
+	sorted := append([]int(nil), leads...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	alerts := make([]models.Alert, 0, len(sorted))
+	for _, lead := range sorted {
+		at := event.Add(-time.Duration(lead) * time.Minute).UTC()
+		alerts = append(alerts, models.Alert{Lead: lead, At: at, Skipped: !at.After(now)})
+	}
+	return alerts
+}
+
+// alertLeads - за сколько минут до события предупреждает каждое из alerts.
+func alertLeads(alerts []models.Alert) []int {
+	leads := make([]int, len(alerts))
+	for i, alert := range alerts {
+		leads[i] = alert.Lead
+	}
+	return leads
+}
+
+// nextFiring - ближайшее неотправленное предупреждение или само событие.
+func nextFiring(alerts []models.Alert, event time.Time) time.Time {
+	next := event.UTC()
+	for _, alert := range alerts {
+		if alert.SentAt == nil && !alert.Skipped && alert.At.Before(next) {
+			next = alert.At
+		}
+	}
+	return next
+}
+
+// eventTime - момент самого события. Пока отправляются предупреждения, utc_time указывает на них,
+// поэтому событие восстанавливается по любому предупреждению.
+func eventTime(reminder models.Reminder) time.Time {
+	if len(reminder.Alerts) == 0 {
+		return reminder.Time
+	}
+	alert := reminder.Alerts[0]
+	return alert.At.Add(time.Duration(alert.Lead) * time.Minute)
+}
+
+// dueAlert возвращает номер предупреждения, ради которого сработало напоминание, или -1, если сработало само событие.
+func dueAlert(reminder models.Reminder) int {
+	for i, alert := range reminder.Alerts {
+		if alert.SentAt == nil && !alert.Skipped && !alert.At.After(reminder.Time) {
+			return i
+		}
+	}
+	return -1
+}
+
+// describeAlerts печатает запланированные предупреждения для ответа на /remindme: "за 1 дн, за 1 ч".
+func describeAlerts(alerts []models.Alert) string {
+	var parts []string
+	skipped := 0
+	for _, alert := range alerts {
+		if alert.Skipped {
+			skipped++
+			continue
+		}
+		parts = append(parts, "за "+formatDuration(time.Duration(alert.Lead)*time.Minute))
+	}
+	text := strings.Join(parts, ", ")
+	if skipped > 0 {
+		if text != "" {
+			text += " "
+		}
+		text += fmt.Sprintf("(пропущено уже прошедших: %d)", skipped)
+	}
+	return text
+}
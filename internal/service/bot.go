@@ -4,6 +4,7 @@ import (
 	"JillBot/internal/models"
 	"JillBot/internal/storage"
 	"JillBot/pkg/ipgeolocation"
+	"JillBot/pkg/timeparse"
 	"context"
 	"encoding/json"
 	"errors"
@@ -11,7 +12,7 @@ import (
 	"io"
 	"log"
 	"os"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,142 +21,196 @@ import (
 
 type BotSrv interface {
 	SetTimezone(ctx context.Context, chatID int64, lat, long float64) error
+	SetTimezoneByName(ctx context.Context, chatID int64, msgText string) (string, error)
+	NeedsReanchor(ctx context.Context, chatID int64, oldZone string) bool
+	ReanchorReminders(ctx context.Context, chatID int64, keepWallClock bool) (string, error)
 	DeleteTimezone(ctx context.Context, chatID int64) bool
 	GetTimezone(ctx context.Context, chatID int64) (models.ChatTimezone, error)
-	RemindMe(chatID int64, msgText string, tz models.ChatTimezone) (string, error)
+	RemindMe(chatID int64, msgText string, tz *models.ChatTimezone) (string, error)
 	//	GetList(msg *telego.Message) (string, error)
+	EditReminder(ctx context.Context, chatID int64, msgText string, tz *models.ChatTimezone) (string, error)
 	DeleteReminder(ctx context.Context, chatID int64, msgText string) (string, error)
 	HelpCommand() (string, error)
-	GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error)
-	MarkReminderAsSent(ctx context.Context, chatID int64, id string) error
-	SetUserPage(ctx context.Context, chatID int64, page int) error
-	GetUserPage(ctx context.Context, chatID int64) int
-	GetListByPage(chatID int64, page int) (string, error)
+	GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error)
+	ClaimDueReminder(ctx context.Context) (models.Reminder, bool, error)
+	ExtendLease(ctx context.Context, reminder models.Reminder, wait time.Duration) (bool, error)
+	StillDue(ctx context.Context, reminder models.Reminder) (bool, error)
+	ReleaseReminder(ctx context.Context, reminder models.Reminder) error
+	DeliveryFailed(ctx context.Context, reminder models.Reminder, sendErr string, retryAfter time.Duration) error
+	FailedReport(ctx context.Context, chatID int64) (string, error)
+	SuspendChat(ctx context.Context, chatID int64) error
+	ResumeOffer(ctx context.Context, chatID int64) (string, bool, error)
+	ResumeChat(ctx context.Context, chatID int64) (string, error)
+	MarkReminderAsSent(ctx context.Context, reminder models.Reminder) error
+	DeliveryText(reminder models.Reminder) (string, bool)
+	AcknowledgeReminder(ctx context.Context, chatID int64, id string) (string, error)
+	SnoozeReminder(ctx context.Context, chatID int64, id, option string) (string, error)
+	SnoozeReminderAt(ctx context.Context, chatID int64, id, msgText string) (string, error)
+	GetListByPage(chatID int64, page int, filter string) (models.ListPage, error)
+	DeleteReminderByNum(ctx context.Context, chatID int64, num int) (string, error)
+	TagsCommand(ctx context.Context, chatID int64) (string, error)
+	SnoozeCommand(ctx context.Context, chatID int64, msgText string) (string, error)
+	PostponeReminder(ctx context.Context, chatID int64, num int) (string, error)
+	ReminderDetails(ctx context.Context, chatID int64, num int) (string, error)
+	DigestCommand(ctx context.Context, chatID int64, msgText string) (string, error)
+	GetDueDigests(ctx context.Context) ([]models.Digest, error)
+	PostponeDay(ctx context.Context, chatID int64, day string) (string, error)
+	QuietCommand(ctx context.Context, chatID int64, msgText string) (string, error)
+	CheckQuietHours(ctx context.Context, reminder models.Reminder) (silent, deferred bool, err error)
 }
 type BotSevice struct {
 	storage.Store
-	ipgeolocation.TimeDiffGetter
+	ipgeolocation.ZoneGetter
+	// Scheduler узнает о новых сроках срабатывания сразу, не дожидаясь выборки из базы. Может быть nil.
+	Scheduler Scheduler
+	// ReplicaID - имя этого экземпляра бота, под которым он захватывает напоминания для отправки.
+	ReplicaID string
+	// Admins - чаты администраторов бота, которым доступен отчет о недоставленных напоминаниях.
+	Admins []int64
 }
 
-func NewBotService(store storage.Store, timeDiff ipgeolocation.TimeDiffGetter) *BotSevice {
-	return &BotSevice{Store: store,
-		TimeDiffGetter: timeDiff}
+// Scheduler - планировщик доставки напоминаний.
+type Scheduler interface {
+	Schedule(at time.Time)
 }
 
-func (b *BotSevice) RemindMe(chatID int64, msgText string, tz models.ChatTimezone) (string, error) {
-	log.Println(msgText)
-	args := strings.TrimPrefix(msgText, "/remindme")
-	args = strings.TrimSpace(args)
-	parts := strings.Fields(args)
-	if len(parts) < 2 {
-		return "Пожалуйста укажи дату/время и действие! Например вот так: /remindme 12:00 сходить в магазин\n" +
-			"Или например если хочешь на напоминание на завтра или через неделю, укажи точную дату, например /remindme 2024-10-10 12:00 сходить в магазин", nil
-
+// schedule сообщает планировщику о новом сроке срабатывания.
+func (s *BotSevice) schedule(at time.Time) {
+	if s.Scheduler != nil {
+		s.Scheduler.Schedule(at)
 	}
-	timeOrDate := parts[:2]
-	fmt.Printf("1st part: %s, 2nd part: %s", timeOrDate[0], timeOrDate[1])
+}
 
-	timeFormat := regexp.MustCompile(`^\d{1,2}[:]\d{2}$`)
-	dateTimeFormat := regexp.MustCompile(`^\d{4}[-]\d{2}[-]\d{2}`)
-	var reminderTime ReminderTimes
-	var err error
-	var action string
-	if timeFormat.MatchString(timeOrDate[0]) {
-		reminderTime, err = timeFormatParse(timeOrDate, tz)
-		if err != nil {
-			return "", err
-		}
-		action = strings.Join(parts[1:], " ")
+func NewBotService(store storage.Store, zoneGetter ipgeolocation.ZoneGetter) *BotSevice {
+	return &BotSevice{Store: store,
+		ZoneGetter: zoneGetter,
+		ReplicaID:  newReplicaID()}
+}
 
-	} else if dateTimeFormat.MatchString(timeOrDate[0]) && timeFormat.MatchString(timeOrDate[1]) {
-		reminderTime, err = dateTimeFormatParse(timeOrDate, tz)
+// RemindMe создает напоминание из текста команды. tz равен nil, если часовой пояс чата неизвестен:
+// тогда принимаются только относительные напоминания ("через 20 минут"), а для остальных
+// возвращается ErrUnknownTimezone.
+func (b *BotSevice) RemindMe(chatID int64, msgText string, tz *models.ChatTimezone) (string, error) {
+	log.Println(msgText)
+	args := strings.TrimPrefix(msgText, "/remindme")
+	usage := "Пожалуйста укажи дату/время и действие! Например вот так: /remindme 12:00 сходить в магазин\n" +
+		"Или например если хочешь на напоминание на завтра или через неделю, укажи точную дату, например /remindme 2024-10-10 12:00 сходить в магазин\n" +
+		"А для повторяющихся напоминаний: /remindme каждый день 09:00 зарядка или /remindme every mon,wed 18:30 спортзал\n" +
+		"Или через сколько напомнить: /remindme через 20 минут выключить плиту или /remindme +2h15m позвонить\n" +
+		"Можно и словами: /remindme завтра в 9 позвонить, /remindme в пятницу в 18:00 бар, /remindme 15 ноября в 10:30 врач"
+	var loc *time.Location
+	if tz != nil {
+		var err error
+		loc, err = chatLocation(*tz)
 		if err != nil {
 			return "", err
 		}
-		action = strings.Join(parts[2:], " ")
-	} else {
-		return "", errors.New("неправильный формат даты или времени")
 	}
-	if isPastTime(reminderTime.UTCtime) {
-		return "", errors.New("ошибка: Указанное время уже прошло. Укажите время в будущем")
+	parsed, err := timeparse.Parse(args, time.Now().UTC(), loc)
+	if errors.Is(err, timeparse.ErrIncomplete) {
+		return usage, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	action, flags, err := parseFlags(parsed.Action)
+	if err != nil {
+		return "", err
+	}
+	if action == "" {
+		return usage, nil
 	}
 
 	reminder := models.Reminder{
 		ChatID:       chatID,
 		Action:       action,
-		Time:         reminderTime.UTCtime,
-		OriginalTime: reminderTime.Originaltime,
+		Time:         parsed.When.UTC(),
+		OriginalTime: wallClock(parsed.When),
+		Recurrence:   recurrenceToModel(parsed.Recurrence),
+		Relative:     parsed.Relative,
+		Nag:          flags.Nag,
+		Urgent:       flags.Urgent,
+		Tags:         parseTags(action),
+		Alerts:       newAlerts(flags.Alerts, parsed.When, time.Now()),
 	}
+	reminder.Time = nextFiring(reminder.Alerts, parsed.When)
 	err = b.Store.AddReminder(context.TODO(), reminder)
 	if err != nil {
 		return "", err
 	}
-	response := fmt.Sprintf("Напоминание установлено! Дата/время: %s, Действие: %s", reminderTime.Originaltime.Format("2006-01-02 15:04"), action)
+	b.schedule(reminder.Time)
+	var response string
+	if tz == nil {
+		response = fmt.Sprintf("Напоминание установлено! Напомню через %s (в %s UTC), Действие: %s",
+			formatDuration(parsed.Offset), parsed.When.UTC().Format("2006-01-02 15:04"), reminder.Action)
+	} else {
+		response = fmt.Sprintf("Напоминание установлено! Дата/время: %s, Действие: %s", reminder.OriginalTime.Format("2006-01-02 15:04"), reminder.Action)
+	}
+	if parsed.Recurrence != nil {
+		response += fmt.Sprintf(", Повтор: %s", parsed.Recurrence)
+	}
+	if flags.Nag != nil {
+		response += fmt.Sprintf(", Буду повторять каждые %d мин, пока не нажмешь «Готово» (до %d раз)", flags.Nag.Interval, flags.Nag.MaxRepeats)
+	}
+	if len(reminder.Alerts) > 0 {
+		response += ", Предупрежу заранее: " + describeAlerts(reminder.Alerts)
+	}
+	if reminder.Urgent {
+		response += ", Срочное: приду и в тихие часы"
+	}
 	log.Println(response)
 	return response, nil
 }
 
-type ReminderTimes struct {
-	UTCtime      time.Time
-	Originaltime time.Time
-}
+// listPageSize - сколько напоминаний показывать на одной странице списка.
+const listPageSize = 5
 
-func timeFormatParse(timeSlice []string, tz models.ChatTimezone) (ReminderTimes, error) {
-	var responseTimes ReminderTimes
-	reminderTime, err := parseTimeWithToday(timeSlice[0])
+// GetListByPage возвращает страницу списка с номером page (с нуля) и напоминания на ней, чтобы под каждым
+// показать кнопки. Ближайшие напоминания идут первыми. Номер страницы приходит из кнопки под сообщением,
+// поэтому после удалений ее уже может не быть: тогда показывается последняя.
+// filter - команда с фильтром, например "/list today" или "/find врач"; пустая строка - весь список.
+func (b *BotSevice) GetListByPage(chatID int64, page int, filter string) (models.ListPage, error) {
+	ctx := context.TODO()
+	filter = normalizeListFilter(filter)
+	conditions, err := b.listConditions(ctx, chatID, filter, time.Now())
 	if err != nil {
-		log.Println(err)
-		return responseTimes, errors.New("ошибка при разборе времени. Формат должен быть HH:mm")
+		return models.ListPage{}, err
 	}
-	responseTimes.Originaltime = reminderTime
-	responseTimes.UTCtime = reminderTime.Add(-time.Duration(tz.Diff_hour) * time.Hour)
-
-	if isPastTime(responseTimes.UTCtime) {
-		responseTimes.UTCtime = responseTimes.UTCtime.Add(24 * time.Hour)
-		responseTimes.Originaltime = responseTimes.Originaltime.Add(24 * time.Hour)
+	header := ""
+	if filter != "" {
+		header = ListFilterMark + filter + "\n"
 	}
-	return responseTimes, nil
-}
-
-func dateTimeFormatParse(timeSlice []string, tz models.ChatTimezone) (ReminderTimes, error) {
-	var responseTimes ReminderTimes
-	date := strings.Join(timeSlice, " ")
-	reminderTime, err := parseTime(date)
+	total, err := b.Store.CountReminders(ctx, chatID, conditions)
 	if err != nil {
-		log.Println(err)
-		return responseTimes, errors.New("ошибка при разборе времени. Формат должен быть HH:mm")
+		return models.ListPage{}, err
 	}
-	responseTimes.Originaltime = reminderTime
-	responseTimes.UTCtime = reminderTime.Add(-time.Duration(tz.Diff_hour) * time.Hour)
-	return responseTimes, nil
-}
-func (b *BotSevice) GetListByPage(chatID int64, updatePage int) (string, error) {
-	ctx := context.TODO()
-	page := b.GetUserPage(ctx, chatID)
-	page += updatePage
-
-	reminders, err := b.Store.GetReminders(ctx, chatID)
+	if total == 0 {
+		if filter != "" {
+			return models.ListPage{Text: header + "Ничего не нашлось", Pages: 1, Filter: filter}, nil
+		}
+		return models.ListPage{Text: "Список напоминаний пуст", Pages: 1}, nil
+	}
+	pages := (total + listPageSize - 1) / listPageSize
+	page = max(0, min(page, pages-1))
+	reminders, err := b.Store.GetRemindersPage(ctx, chatID, conditions, page*listPageSize, listPageSize)
 	if err != nil {
-		return "", err
+		return models.ListPage{}, err
 	}
-	var message string
-	if len(reminders) == 0 {
-		return "Список напоминаний пуст", nil
-	}
-	maxPages := len(reminders) / 5
-	if page < 0 {
-		page = 0
-	} else if page > maxPages {
-		page = maxPages
-	}
-	message += fmt.Sprintf("У вас %d напоминаний:\n", len(reminders))
-	for i := page * 5; i < len(reminders) && i < page*5+5; i++ {
-		message += fmt.Sprintf("ID: %s\n⏰ Время: %s\n📋 Действие: %s\n\n",
-			reminders[i].ID, reminders[i].OriginalTime.Format("2006-01-02 15:04:05"), reminders[i].Action)
-	}
-	message += fmt.Sprintf("Страница №%d из %d", page+1, maxPages+1)
-	b.SetUserPage(ctx, chatID, page)
-	return message, nil
+	message := header + fmt.Sprintf("У вас %d напоминаний:\n", total)
+	if filter != "" {
+		message = header + fmt.Sprintf("Найдено %d напоминаний:\n", total)
+	}
+	for _, reminder := range reminders {
+		message += fmt.Sprintf("№%d\n⏰ Время: %s\n📋 Действие: %s\n",
+			reminder.Num, reminder.OriginalTime.Format("2006-01-02 15:04:05"), reminder.Action)
+		if reminder.DeferredFrom != nil {
+			loc := b.reminderLocation(ctx, reminder)
+			message += fmt.Sprintf("🌙 Тихие часы: придет в %s\n", reminder.Time.In(loc).Format("2006-01-02 15:04"))
+		}
+		message += "\n"
+	}
+	message += fmt.Sprintf("Страница №%d из %d", page+1, pages)
+	return models.ListPage{Text: message, Reminders: reminders, Page: page, Pages: pages, Filter: filter}, nil
 }
 
 // func (b *BotSevice) GetList(msg *telego.Message) (string, error) {
@@ -175,49 +230,108 @@ func (b *BotSevice) GetListByPage(chatID int64, updatePage int) (string, error)
 //		}
 //		return message, nil
 //	}
-func parseTimeWithToday(timeStr string) (time.Time, error) {
+
+func (s *BotSevice) GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error) {
+	return s.Store.GetUpcomingReminders(ctx, until)
+}
+
+// MarkReminderAsSent отмечает напоминание отправленным. Отправленное предупреждение заранее
+// отмечается в списке, а напоминание ждет следующего предупреждения или самого события.
+// Напоминание с !nag остается активным
+// и повторяется, пока не будет подтверждено или не кончатся повторы. После последней отправки
+// разовое напоминание снимается с активных, а повторяющееся переносится на следующее срабатывание
+// в местном времени пользователя.
+func (s *BotSevice) MarkReminderAsSent(ctx context.Context, reminder models.Reminder) error {
 	now := time.Now().UTC()
-	layout := "15:04"
-	parsedTime, err := time.Parse(layout, timeStr)
-	if err != nil {
-		return time.Time{}, err
+	if i := dueAlert(reminder); i >= 0 {
+		reminder.Alerts[i].SentAt = &now
+		return s.Store.UpdateAlerts(ctx, reminder.ID, s.ReplicaID, reminder.Alerts, nextFiring(reminder.Alerts, eventTime(reminder)))
+	}
+	if reminder.Nag != nil && reminder.NagCount < reminder.Nag.MaxRepeats {
+		next := now.Add(time.Duration(reminder.Nag.Interval) * time.Minute)
+		s.schedule(next)
+		return s.Store.ScheduleNag(ctx, reminder.ID, s.ReplicaID, now, next)
 	}
-	reminderTime := time.Date(now.Year(), now.Month(), now.Day(), parsedTime.Hour(), parsedTime.Minute(), 0, 0, now.UTC().Location())
-	return reminderTime, nil
+	if reminder.Recurrence != nil {
+		next, firing, alerts := s.nextOccurrence(ctx, reminder, now)
+		return s.Store.RescheduleReminder(ctx, reminder.ID, s.ReplicaID, firing, wallClock(next), alerts)
+	}
+	return s.Store.MarkReminderAsDelivered(ctx, reminder.ID, s.ReplicaID, now)
 }
-func parseTime(timeStr string) (time.Time, error) {
+
+// AcknowledgeReminder отмечает напоминание выполненным по кнопке "Готово" и возвращает новый текст сообщения.
+func (s *BotSevice) AcknowledgeReminder(ctx context.Context, chatID int64, id string) (string, error) {
+	reminder, err := s.Store.GetReminder(ctx, chatID, id)
+	if err != nil {
+		log.Println(err)
+		return "", errReminderNotFound
+	}
 	now := time.Now().UTC()
-	layout := "2006-01-02 15:04"
-	parsedTime, err := time.Parse(layout, timeStr)
+	changes, err := s.Store.AcknowledgeReminder(ctx, chatID, id, now)
 	if err != nil {
-		return time.Time{}, err
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if changes == 0 {
+		return reminder.Action + "\n\nЭто напоминание уже не ждет подтверждения", nil
+	}
+	if reminder.Recurrence != nil {
+		if err := s.rescheduleRecurring(ctx, reminder, time.Now()); err != nil {
+			log.Println(err)
+			return "", errors.New("Похоже что-то сломалось...")
+		}
 	}
-	reminderTime := time.Date(parsedTime.Year(), parsedTime.Month(), parsedTime.Day(), parsedTime.Hour(), parsedTime.Minute(), 0, 0, now.Location())
-	return reminderTime, nil
+	return reminder.Action + "\n\n✅ Готово", nil
 }
-func isPastTime(date time.Time) bool {
-	now := time.Now().UTC()
-	fmt.Printf("\n Сейчас: %v \n Проверяемая дата: %v", now, date)
-	isPast := date.Before(now)
-	return isPast
+
+// rescheduleRecurring переносит повторяющееся напоминание на первое срабатывание после notBefore
+// по просьбе пользователя. Напоминание остается активным, даже если уже было снято с активных.
+func (s *BotSevice) rescheduleRecurring(ctx context.Context, reminder models.Reminder, notBefore time.Time) error {
+	next, firing, alerts := s.nextOccurrence(ctx, reminder, notBefore)
+	return s.Store.ReactivateReminder(ctx, reminder.ID, firing, wallClock(next), alerts)
 }
 
-func (s *BotSevice) GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error) {
-	return s.Store.GetUpcomingReminders(ctx)
+// nextOccurrence - первое срабатывание повторяющегося напоминания после notBefore: само событие
+// по часам чата, момент ближайшей отправки (предупреждение заранее или событие) и новые предупреждения.
+func (s *BotSevice) nextOccurrence(ctx context.Context, reminder models.Reminder, notBefore time.Time) (next, firing time.Time, alerts []models.Alert) {
+	loc := s.reminderLocation(ctx, reminder)
+	next = recurrenceFromModel(reminder.Recurrence).Next(fromWallClock(reminder.OriginalTime, loc), notBefore)
+	alerts = newAlerts(alertLeads(reminder.Alerts), next, time.Now())
+	return next, nextFiring(alerts, next), alerts
 }
 
-func (s *BotSevice) MarkReminderAsSent(ctx context.Context, chatID int64, id string) error {
-	_, err := s.Store.MarkReminderAsInactive(ctx, chatID, id)
-	return err
+// DeliveryText - текст сообщения при срабатывании напоминания. alert сообщает, что это предупреждение
+// заранее, а не само событие: под ним не нужна кнопка "Готово".
+func (s *BotSevice) DeliveryText(reminder models.Reminder) (text string, alert bool) {
+	if i := dueAlert(reminder); i >= 0 {
+		left := time.Until(eventTime(reminder))
+		return fmt.Sprintf("⏳ %s\n\nДо события осталось %s (%s)",
+			reminder.Action, formatDuration(left), reminder.OriginalTime.Format("2006-01-02 15:04")), true
+	}
+	if reminder.NagCount > 0 && reminder.Nag != nil {
+		return fmt.Sprintf("🔁 %s\n\nНапоминаю еще раз (%d из %d). Нажми «Готово», когда сделаешь",
+			reminder.Action, reminder.NagCount, reminder.Nag.MaxRepeats), false
+	}
+	return reminder.Action, false
 }
 func (s *BotSevice) DeleteReminder(ctx context.Context, chatID int64, msgText string) (string, error) {
-	args := strings.TrimPrefix(msgText, "/del")
-	id := strings.TrimSpace(args)
-	parts := strings.Fields(args)
-	if len(parts) == 0 || len(parts) > 1 {
-		return "Пожалуйста укажи айди напоминания! \n Например: /del 6701dca27a3481be8353eee5", nil
+	parts := strings.Fields(strings.TrimPrefix(msgText, "/del"))
+	if len(parts) != 1 {
+		return "Пожалуйста укажи номер напоминания из /list! \n Например: /del 3", nil
 	}
-	changes, err := s.Store.MarkReminderAsInactive(ctx, chatID, id)
+	if strings.HasPrefix(parts[0], "#") {
+		return s.deleteByTag(ctx, chatID, parts[0])
+	}
+	num, ok := parseNum(parts[0])
+	if !ok {
+		return "Пожалуйста укажи номер напоминания из /list! \n Например: /del 3", nil
+	}
+	return s.DeleteReminderByNum(ctx, chatID, num)
+}
+
+// DeleteReminderByNum снимает с активных напоминание с номером num, для /del и кнопки в списке.
+func (s *BotSevice) DeleteReminderByNum(ctx context.Context, chatID int64, num int) (string, error) {
+	changes, err := s.Store.MarkReminderAsInactive(ctx, chatID, num)
 	if err != nil {
 		log.Println(err)
 		return "", errors.New("Похоже что-то сломалось...")
@@ -227,6 +341,16 @@ func (s *BotSevice) DeleteReminder(ctx context.Context, chatID int64, msgText st
 	}
 	return "Напоминание удалено успешно", nil
 }
+
+// parseNum разбирает номер напоминания: "3" или "№3". "#2024" - это тег, а не номер.
+func parseNum(s string) (int, bool) {
+	s = strings.TrimPrefix(s, "№")
+	num, err := strconv.Atoi(s)
+	if err != nil || num < 1 {
+		return 0, false
+	}
+	return num, true
+}
 func (s *BotSevice) HelpCommand() (string, error) {
 	commands, err := s.LoadCommands()
 	if err != nil {
@@ -256,19 +380,19 @@ func (s *BotSevice) LoadCommands() ([]models.Command, error) {
 }
 
 func (s *BotSevice) SetTimezone(ctx context.Context, chatID int64, lat, long float64) error {
-	diffhour, err := s.TimeDiffGetter.GetTimeDiff(lat, long)
+	zone, err := s.ZoneGetter.GetZone(lat, long)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 	if _, err := s.Store.GetTimezone(ctx, chatID); err == nil {
-		err := s.Store.UpdateTimezone(ctx, chatID, lat, long, diffhour)
+		err := s.Store.UpdateTimezone(ctx, chatID, lat, long, zone)
 		if err != nil {
 			log.Println(err)
 		}
 		return err
 	}
-	err = s.Store.AddTimezone(ctx, chatID, lat, long, diffhour)
+	err = s.Store.AddTimezone(ctx, chatID, lat, long, zone)
 	if err != nil {
 		log.Println(err)
 		return err
@@ -277,6 +401,89 @@ func (s *BotSevice) SetTimezone(ctx context.Context, chatID int64, lat, long flo
 
 }
 
+// SetTimezoneByName задает часовой пояс без геолокации: /settz Europe/Moscow, /settz UTC+3, /settz Москва.
+func (s *BotSevice) SetTimezoneByName(ctx context.Context, chatID int64, msgText string) (string, error) {
+	query := strings.TrimSpace(strings.TrimPrefix(msgText, "/settz"))
+	if query == "" {
+		return "Пожалуйста укажи часовой пояс или город! Например: /settz Europe/Moscow, /settz UTC+3 или /settz Москва", nil
+	}
+	zone, err := resolveZone(query)
+	if errors.Is(err, ErrUnknownZone) {
+		return fmt.Sprintf("Не знаю такого часового пояса: «%s». Попробуй указать сдвиг, например /settz UTC+3, или выбери город через /settz", query), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := s.Store.SetZone(ctx, chatID, zone); err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	loc, _ := loadZone(zone)
+	now := time.Now().In(loc)
+	return fmt.Sprintf("Хорошо, я запомнила: %s (сейчас там %s, UTC%s)", zone, now.Format("15:04"), now.Format("-07:00")), nil
+}
+
+// NeedsReanchor сообщает, что часовой пояс чата сменился с oldZone и есть напоминания,
+// время которых надо пересчитать. Спросить пользователя как - задача обработчика.
+func (s *BotSevice) NeedsReanchor(ctx context.Context, chatID int64, oldZone string) bool {
+	if oldZone == "" {
+		return false
+	}
+	tz, err := s.Store.GetTimezone(ctx, chatID)
+	if err != nil || tz.Zone == oldZone {
+		return false
+	}
+	reminders, err := s.Store.GetReminders(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+	return len(reminders) > 0
+}
+
+// ReanchorReminders пересчитывает активные напоминания чата под его текущий часовой пояс.
+// keepWallClock оставляет прежнее время на часах (в 9:00 по местному и после переезда),
+// иначе сохраняется момент срабатывания, а пересчитывается время на часах.
+// Напоминания "через N минут" всегда срабатывают в тот же момент.
+func (s *BotSevice) ReanchorReminders(ctx context.Context, chatID int64, keepWallClock bool) (string, error) {
+	tz, err := s.Store.GetTimezone(ctx, chatID)
+	if err != nil {
+		return "", ErrUnknownTimezone
+	}
+	loc, err := chatLocation(tz)
+	if err != nil {
+		return "", err
+	}
+	reminders, err := s.Store.GetReminders(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if len(reminders) == 0 {
+		return "Активных напоминаний нет, пересчитывать нечего", nil
+	}
+	for i := range reminders {
+		if keepWallClock && !reminders[i].Relative {
+			event := fromWallClock(reminders[i].OriginalTime, loc).UTC()
+			shift := event.Sub(eventTime(reminders[i]))
+			for j := range reminders[i].Alerts {
+				reminders[i].Alerts[j].At = reminders[i].Alerts[j].At.Add(shift)
+			}
+			reminders[i].Time = nextFiring(reminders[i].Alerts, event)
+		} else {
+			reminders[i].OriginalTime = wallClock(eventTime(reminders[i]).In(loc))
+		}
+	}
+	if err := s.Store.RescheduleReminders(ctx, reminders); err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if keepWallClock {
+		return fmt.Sprintf("Готово, напоминаний пересчитано: %d. Они сработают в то же время по местным часам", len(reminders)), nil
+	}
+	return fmt.Sprintf("Готово, напоминаний пересчитано: %d. Они сработают в тот же момент, что и раньше", len(reminders)), nil
+}
+
 func (s *BotSevice) GetTimezone(ctx context.Context, chatID int64) (models.ChatTimezone, error) {
 	tz, err := s.Store.GetTimezone(ctx, chatID)
 	if err != nil {
@@ -297,3 +504,46 @@ func (s *BotSevice) DeleteTimezone(ctx context.Context, chatID int64) bool {
 	}
 	return false
 }
+
+// MigrateTimezones переводит записи, сохраненные со сдвигом в целых часах, на IANA-зоны.
+// Зона определяется заново по координатам, а если это не удалось - берется Etc/GMT с тем же сдвигом.
+func (s *BotSevice) MigrateTimezones(ctx context.Context) (int, error) {
+	legacy, err := s.Store.GetLegacyTimezones(ctx)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, tz := range legacy {
+		zone, err := s.ZoneGetter.GetZone(tz.Latitude, tz.Longitude)
+		if err != nil {
+			log.Printf("Не удалось определить зону для чата %d, используется сдвиг %d ч: %v", tz.ChatID, tz.DiffHour, err)
+			zone = fixedZoneName(tz.DiffHour)
+		}
+		if err := s.Store.UpdateTimezone(ctx, tz.ChatID, tz.Latitude, tz.Longitude, zone); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// MigrateReminderNumbers выдает короткие номера активным напоминаниям, созданным до их появления.
+// Номера выдаются по порядку срабатывания из того же счетчика, что и для новых напоминаний.
+func (s *BotSevice) MigrateReminderNumbers(ctx context.Context) (int, error) {
+	reminders, err := s.Store.GetUnnumberedReminders(ctx)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, reminder := range reminders {
+		num, err := s.Store.NextReminderNum(ctx, reminder.ChatID)
+		if err != nil {
+			return migrated, err
+		}
+		if err := s.Store.SetReminderNum(ctx, reminder.ID, num); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"JillBot/internal/models"
+	mock_service "JillBot/internal/service/mocks"
 	mock_storage "JillBot/internal/storage/mocks"
 	mock_ipgeolocation "JillBot/pkg/ipgeolocation/mocks"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,7 +50,7 @@ func TestService_RemindMe(t *testing.T) {
 					time.Now().UTC().Month(),
 					time.Now().UTC().Add(24*time.Hour).Day(), 0, 1, 0, 0, time.UTC),
 			},
-			timezone: models.ChatTimezone{id, 0.0, 0.0, 0},
+			timezone: models.ChatTimezone{ChatID: id, Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
 				r.EXPECT().AddReminder(gomock.Any(), reminder).Return(nil)
 			},
@@ -64,7 +66,7 @@ func TestService_RemindMe(t *testing.T) {
 				Time:         time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
 				OriginalTime: time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
 			},
-			timezone: models.ChatTimezone{id, 0.0, 0.0, 0},
+			timezone: models.ChatTimezone{ChatID: id, Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
 				r.EXPECT().AddReminder(gomock.Any(), reminder).Return(nil)
 			},
@@ -73,20 +75,88 @@ func TestService_RemindMe(t *testing.T) {
 		{
 			name:         "ShortMsg",
 			msgText:      "/remindme 12:00",
+			timezone:     models.ChatTimezone{Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
 			wantResp: "Пожалуйста укажи дату/время и действие! Например вот так: /remindme 12:00 сходить в магазин\n" +
-				"Или например если хочешь на напоминание на завтра или через неделю, укажи точную дату, например /remindme 2024-10-10 12:00 сходить в магазин",
+				"Или например если хочешь на напоминание на завтра или через неделю, укажи точную дату, например /remindme 2024-10-10 12:00 сходить в магазин\n" +
+				"А для повторяющихся напоминаний: /remindme каждый день 09:00 зарядка или /remindme every mon,wed 18:30 спортзал\n" +
+				"Или через сколько напомнить: /remindme через 20 минут выключить плиту или /remindme +2h15m позвонить\n" +
+				"Можно и словами: /remindme завтра в 9 позвонить, /remindme в пятницу в 18:00 бар, /remindme 15 ноября в 10:30 врач",
+		},
+		{
+			name:    "OKrecurring",
+			msgText: "/remindme каждый месяц 2040-12-12 12:00 test",
+			chatID:  int64(1),
+			reminder: models.Reminder{
+				ChatID:       int64(1),
+				Action:       "test",
+				Time:         time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
+				OriginalTime: time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
+				Recurrence:   &models.Recurrence{Frequency: "monthly", Day: 12},
+			},
+			timezone: models.ChatTimezone{ChatID: id, Zone: "UTC"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().AddReminder(gomock.Any(), reminder).Return(nil)
+			},
+			wantResp: "Напоминание установлено! Дата/время: 2040-12-12 12:00, Действие: test, Повтор: каждый месяц 12 числа",
+		},
+		{
+			name:    "OKnag",
+			msgText: "/remindme 2040-12-12 12:00 !nag15x4 test",
+			chatID:  int64(1),
+			reminder: models.Reminder{
+				ChatID:       int64(1),
+				Action:       "test",
+				Time:         time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
+				OriginalTime: time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
+				Nag:          &models.Nag{Interval: 15, MaxRepeats: 4},
+			},
+			timezone: models.ChatTimezone{ChatID: id, Zone: "UTC"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().AddReminder(gomock.Any(), reminder).Return(nil)
+			},
+			wantResp: "Напоминание установлено! Дата/время: 2040-12-12 12:00, Действие: test, Буду повторять каждые 15 мин, пока не нажмешь «Готово» (до 4 раз)",
+		},
+		{
+			name:    "OKalerts",
+			msgText: "/remindme 2040-12-12 12:00 экзамен !1d !1h",
+			chatID:  int64(1),
+			reminder: models.Reminder{
+				ChatID:       int64(1),
+				Action:       "экзамен",
+				Time:         time.Date(2040, 12, 11, 12, 0, 0, 0, time.UTC),
+				OriginalTime: time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC),
+				Alerts: []models.Alert{
+					{Lead: 24 * 60, At: time.Date(2040, 12, 11, 12, 0, 0, 0, time.UTC)},
+					{Lead: 60, At: time.Date(2040, 12, 12, 11, 0, 0, 0, time.UTC)},
+				},
+			},
+			timezone: models.ChatTimezone{ChatID: id, Zone: "UTC"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().AddReminder(gomock.Any(), reminder).Return(nil)
+			},
+			wantResp: "Напоминание установлено! Дата/время: 2040-12-12 12:00, Действие: экзамен, Предупрежу заранее: за 1 дн, за 1 ч",
+		},
+		{
+			name:         "UnknownRecurrence",
+			msgText:      "/remindme каждый вечер 12:00 test",
+			timezone:     models.ChatTimezone{Zone: "UTC"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
+			wantErr:      true,
+			Error:        errors.New("не понимаю, как часто повторять напоминание"),
 		},
 		{
 			name:         "InvalidFormat",
 			msgText:      "/remindme 1200 test",
+			timezone:     models.ChatTimezone{Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
 			wantErr:      true,
-			Error:        errors.New("неправильный формат даты или времени"),
+			Error:        errors.New("неправильный формат даты или времени: не поняла «1200»"),
 		},
 		{
 			name:         "InvalidTimeFormat",
 			msgText:      "/remindme 25:00 test",
+			timezone:     models.ChatTimezone{Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
 			wantErr:      true,
 			Error:        errors.New("ошибка при разборе времени. Формат должен быть HH:mm"),
@@ -94,6 +164,7 @@ func TestService_RemindMe(t *testing.T) {
 		{
 			name:         "PastTime",
 			msgText:      "/remindme 1995-05-25 12:00 test",
+			timezone:     models.ChatTimezone{Zone: "UTC"},
 			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
 			wantErr:      true,
 			Error:        errors.New("ошибка: Указанное время уже прошло. Укажите время в будущем"),
@@ -105,9 +176,9 @@ func TestService_RemindMe(t *testing.T) {
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
 			tt.mockBehavior(repo, tt.reminder)
-			timeDiffGetter := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
-			srv := NewBotService(repo, timeDiffGetter)
-			msg, err := srv.RemindMe(tt.chatID, tt.msgText, tt.timezone)
+			zoneGetter := mock_ipgeolocation.NewMockZoneGetter(ctrl)
+			srv := NewBotService(repo, zoneGetter)
+			msg, err := srv.RemindMe(tt.chatID, tt.msgText, &tt.timezone)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Equal(t, err, tt.Error)
@@ -120,117 +191,11 @@ func TestService_RemindMe(t *testing.T) {
 
 }
 
-func TestService_timeFormatParse(t *testing.T) {
-	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
-	testTable := []struct {
-		name         string
-		timeSlice    []string
-		timezone     models.ChatTimezone
-		mockBehavior mockBehavior
-		wantErr      bool
-		Error        error
-		wantResp     ReminderTimes
-	}{
-		{
-			name:      "OK",
-			timeSlice: []string{"23:59", ""},
-			timezone:  models.ChatTimezone{Diff_hour: 0},
-			wantResp: ReminderTimes{
-				UTCtime: time.Date(time.Now().UTC().Year(),
-					time.Now().UTC().Month(),
-					time.Now().UTC().Day(),
-					23, 59, 0, 0, time.UTC),
-				Originaltime: time.Date(time.Now().UTC().Year(),
-					time.Now().UTC().Month(),
-					time.Now().UTC().Day(),
-					23, 59, 0, 0, time.UTC),
-			},
-		},
-		{
-			name:      "UnparseableSlice",
-			timeSlice: []string{"1200", ""},
-			timezone:  models.ChatTimezone{Diff_hour: 0},
-			wantErr:   true,
-			Error:     errors.New("ошибка при разборе времени. Формат должен быть HH:mm"),
-		},
-		{
-			name:      "PastButOkay",
-			timeSlice: []string{"00:01", ""},
-			timezone:  models.ChatTimezone{Diff_hour: 0},
-			wantResp: ReminderTimes{
-				UTCtime:      time.Date(time.Now().UTC().Year(), time.Now().UTC().Month(), time.Now().UTC().Add(24*time.Hour).Day(), 0, 1, 0, 0, time.UTC),
-				Originaltime: time.Date(time.Now().UTC().Year(), time.Now().UTC().Month(), time.Now().UTC().Add(24*time.Hour).Day(), 0, 1, 0, 0, time.UTC),
-			},
-		},
-	}
-	for _, tt := range testTable {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			ReminderTimes, err := timeFormatParse(tt.timeSlice, tt.timezone)
-			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Equal(t, err, tt.Error)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, ReminderTimes, tt.wantResp)
-			}
-		})
-	}
-
-}
-
-func TestService_dateTimeFormatParse(t *testing.T) {
-	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
-	testTable := []struct {
-		name         string
-		timeSlice    []string
-		timezone     models.ChatTimezone
-		mockBehavior mockBehavior
-		wantErr      bool
-		Error        error
-		wantResp     ReminderTimes
-	}{
-		{
-			name:      "OK",
-			timeSlice: []string{"2025-10-16", "12:00"},
-			timezone:  models.ChatTimezone{Diff_hour: 0},
-			wantResp: ReminderTimes{
-				UTCtime:      time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
-				Originaltime: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
-			},
-		},
-		{
-			name:      "UnparseableSlice",
-			timeSlice: []string{"1200", ""},
-			timezone:  models.ChatTimezone{Diff_hour: 0},
-			wantErr:   true,
-			Error:     errors.New("ошибка при разборе времени. Формат должен быть HH:mm"),
-		},
-	}
-	for _, tt := range testTable {
-		t.Run(tt.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-			ReminderTimes, err := dateTimeFormatParse(tt.timeSlice, tt.timezone)
-			if tt.wantErr {
-				assert.Error(t, err)
-				assert.Equal(t, err, tt.Error)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, ReminderTimes, tt.wantResp)
-			}
-		})
-	}
-
-}
-
 func TestService_GetListByPage(t *testing.T) {
 	type mockBehavior func(r *mock_storage.MockStore, chatID int64, page int)
 	testTable := []struct {
 		name         string
 		chatID       int64
-		updatePage   int
 		page         int
 		mockBehavior mockBehavior
 		reminders    []models.Reminder
@@ -239,82 +204,89 @@ func TestService_GetListByPage(t *testing.T) {
 		wantResp     string
 	}{
 		{
-			name:       "OK",
-			chatID:     int64(1),
-			updatePage: 0,
-			page:       0,
+			name:   "OK",
+			chatID: int64(1),
+			page:   0,
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64, page int) {
-				r.EXPECT().GetUserPage(context.TODO(), chatID).Return(0)
-				r.EXPECT().GetReminders(context.TODO(), chatID).Return(
+				r.EXPECT().CountReminders(context.TODO(), chatID, models.ReminderFilter{}).Return(2, nil)
+				r.EXPECT().GetRemindersPage(context.TODO(), chatID, models.ReminderFilter{}, 0, 5).Return(
 
 					[]models.Reminder{
 						{
 							ID:           "1",
+							Num:          1,
 							OriginalTime: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
 							Action:       "test",
 						},
 						{
 							ID:           "2",
+							Num:          2,
 							OriginalTime: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
 							Action:       "test",
 						},
 					}, nil,
 				)
-				r.EXPECT().SetUserPage(context.TODO(), chatID, 0)
 			},
 			wantResp: "У вас 2 напоминаний:\n" +
-				"ID: 1\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
-				"ID: 2\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
+				"№1\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
+				"№2\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
 				"Страница №1 из 1",
 		},
 		{
-			name:       "OkOutOfRangePage",
-			chatID:     int64(1),
-			updatePage: 1,
-			page:       10000,
+			name:   "OkOutOfRangePage",
+			chatID: int64(1),
+			page:   10000,
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64, page int) {
-				r.EXPECT().GetUserPage(context.TODO(), chatID).Return(0)
-				r.EXPECT().GetReminders(context.TODO(), chatID).Return(
+				r.EXPECT().CountReminders(context.TODO(), chatID, models.ReminderFilter{}).Return(2, nil)
+				r.EXPECT().GetRemindersPage(context.TODO(), chatID, models.ReminderFilter{}, 0, 5).Return(
 
 					[]models.Reminder{
 						{
 							ID:           "1",
+							Num:          1,
 							OriginalTime: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
 							Action:       "test",
 						},
 						{
 							ID:           "2",
+							Num:          2,
 							OriginalTime: time.Date(2025, 10, 16, 12, 0, 0, 0, time.UTC),
 							Action:       "test",
 						},
 					}, nil,
 				)
-				r.EXPECT().SetUserPage(context.TODO(), chatID, 0)
 			},
 			wantResp: "У вас 2 напоминаний:\n" +
-				"ID: 1\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
-				"ID: 2\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
+				"№1\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
+				"№2\n⏰ Время: 2025-10-16 12:00:00\n📋 Действие: test\n\n" +
 				"Страница №1 из 1",
 		},
 		{
-			name:       "EmptyList",
-			chatID:     int64(1),
-			updatePage: 0,
-			page:       0,
+			name:   "EmptyList",
+			chatID: int64(1),
+			page:   0,
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64, page int) {
-				r.EXPECT().GetUserPage(context.TODO(), chatID).Return(0)
-				r.EXPECT().GetReminders(context.TODO(), chatID).Return(nil, nil)
+				r.EXPECT().CountReminders(context.TODO(), chatID, models.ReminderFilter{}).Return(0, nil)
 			},
 			wantResp: "Список напоминаний пуст",
 		},
 		{
-			name:       "GetListError",
-			chatID:     int64(1),
-			updatePage: 0,
-			page:       0,
+			name:   "GetPageError",
+			chatID: int64(1),
+			page:   0,
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, page int) {
+				r.EXPECT().CountReminders(context.TODO(), chatID, models.ReminderFilter{}).Return(2, nil)
+				r.EXPECT().GetRemindersPage(context.TODO(), chatID, models.ReminderFilter{}, 0, 5).Return(nil, errors.New("неполадки"))
+			},
+			wantErr: true,
+			Error:   errors.New("неполадки"),
+		},
+		{
+			name:   "GetListError",
+			chatID: int64(1),
+			page:   0,
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64, page int) {
-				r.EXPECT().GetUserPage(context.TODO(), chatID).Return(0)
-				r.EXPECT().GetReminders(context.TODO(), chatID).Return(nil, errors.New("неполадки"))
+				r.EXPECT().CountReminders(context.TODO(), chatID, models.ReminderFilter{}).Return(0, errors.New("неполадки"))
 			},
 			wantErr: true,
 			Error:   errors.New("неполадки"),
@@ -326,28 +298,58 @@ func TestService_GetListByPage(t *testing.T) {
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
 			tt.mockBehavior(repo, tt.chatID, tt.page)
-			timeDiffGetter := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
-			srv := NewBotService(repo, timeDiffGetter)
-			msg, err := srv.GetListByPage(tt.chatID, tt.updatePage)
+			zoneGetter := mock_ipgeolocation.NewMockZoneGetter(ctrl)
+			srv := NewBotService(repo, zoneGetter)
+			list, err := srv.GetListByPage(tt.chatID, tt.page, "")
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Equal(t, err, tt.Error)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, msg, tt.wantResp)
+				assert.Equal(t, list.Text, tt.wantResp)
 			}
 		})
 	}
 
 }
 
+func TestService_GetListByPageStalePage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	var reminders []models.Reminder
+	for i := 1; i <= 6; i++ {
+		reminders = append(reminders, models.Reminder{Num: i, Action: "test"})
+	}
+	gomock.InOrder(
+		repo.EXPECT().CountReminders(gomock.Any(), int64(1), models.ReminderFilter{}).Return(6, nil),
+		repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{}, 5, 5).Return(reminders[5:], nil),
+		repo.EXPECT().CountReminders(gomock.Any(), int64(1), models.ReminderFilter{}).Return(5, nil),
+		repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{}, 0, 5).Return(reminders[:5], nil),
+	)
+	srv := NewBotService(repo, nil)
+
+	list, err := srv.GetListByPage(1, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Page)
+	assert.Equal(t, 2, list.Pages)
+	assert.Equal(t, []models.Reminder{reminders[5]}, list.Reminders)
+
+	// Шестое напоминание удалили, второй страницы больше нет
+	list, err = srv.GetListByPage(1, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, list.Page)
+	assert.Equal(t, 1, list.Pages)
+	assert.Len(t, list.Reminders, 5)
+}
+
 func TestService_DeleteReminder(t *testing.T) {
-	type mockBehavior func(r *mock_storage.MockStore, chatID int64, id string)
+	type mockBehavior func(r *mock_storage.MockStore, chatID int64, num int)
 	testTable := []struct {
 		name         string
 		chatID       int64
 		msgText      string
-		id           string
+		num          int
 		mockBehavior mockBehavior
 		wantErr      bool
 		Error        error
@@ -357,9 +359,9 @@ func TestService_DeleteReminder(t *testing.T) {
 			name:    "OK",
 			chatID:  int64(1),
 			msgText: "/del 1",
-			id:      "1",
-			mockBehavior: func(r *mock_storage.MockStore, chatID int64, id string) {
-				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, id).Return(int64(1), nil)
+			num:     1,
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, num).Return(int64(1), nil)
 			},
 			wantResp: "Напоминание удалено успешно",
 		},
@@ -367,16 +369,61 @@ func TestService_DeleteReminder(t *testing.T) {
 			name:         "ShortMsg",
 			chatID:       int64(1),
 			msgText:      "/del 1 1 1 1",
-			mockBehavior: func(r *mock_storage.MockStore, chatID int64, id string) {},
-			wantResp:     "Пожалуйста укажи айди напоминания! \n Например: /del 6701dca27a3481be8353eee5",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {},
+			wantResp:     "Пожалуйста укажи номер напоминания из /list! \n Например: /del 3",
+		},
+		{
+			name:         "NotANumber",
+			chatID:       int64(1),
+			msgText:      "/del 6701dca27a3481be8353eee5",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {},
+			wantResp:     "Пожалуйста укажи номер напоминания из /list! \n Например: /del 3",
+		},
+		{
+			name:    "NumberSign",
+			chatID:  int64(1),
+			msgText: "/del №3",
+			num:     3,
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, num).Return(int64(1), nil)
+			},
+			wantResp: "Напоминание удалено успешно",
+		},
+		{
+			// "#2024" - тег, а не напоминание №2024
+			name:    "NumericTag",
+			chatID:  int64(1),
+			msgText: "/del #2024",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkRemindersAsInactiveByTag(gomock.Any(), chatID, "2024").Return(int64(2), nil)
+			},
+			wantResp: "Удалено напоминаний с тегом #2024: 2",
+		},
+		{
+			name:    "Tag",
+			chatID:  int64(1),
+			msgText: "/del #Финансы",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkRemindersAsInactiveByTag(gomock.Any(), chatID, "финансы").Return(int64(4), nil)
+			},
+			wantResp: "Удалено напоминаний с тегом #финансы: 4",
+		},
+		{
+			name:    "TagNotFound",
+			chatID:  int64(1),
+			msgText: "/del #отпуск",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkRemindersAsInactiveByTag(gomock.Any(), chatID, "отпуск").Return(int64(0), nil)
+			},
+			wantResp: "Напоминаний с тегом #отпуск не нашлось",
 		},
 		{
 			name:    "DeleteError",
 			chatID:  int64(1),
 			msgText: "/del 1",
-			id:      "1",
-			mockBehavior: func(r *mock_storage.MockStore, chatID int64, id string) {
-				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, id).Return(int64(1), errors.New("ads"))
+			num:     1,
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, num).Return(int64(1), errors.New("ads"))
 			},
 			wantErr: true,
 			Error:   errors.New("Похоже что-то сломалось..."),
@@ -385,9 +432,9 @@ func TestService_DeleteReminder(t *testing.T) {
 			name:    "NoChanges",
 			chatID:  int64(1),
 			msgText: "/del 1",
-			id:      "1",
-			mockBehavior: func(r *mock_storage.MockStore, chatID int64, id string) {
-				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, id).Return(int64(0), nil)
+			num:     1,
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, num int) {
+				r.EXPECT().MarkReminderAsInactive(gomock.Any(), chatID, num).Return(int64(0), nil)
 			},
 			wantResp: "Напоминание не было найдено",
 		},
@@ -397,9 +444,9 @@ func TestService_DeleteReminder(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
-			tt.mockBehavior(repo, tt.chatID, tt.id)
-			timeDiffGetter := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
-			srv := NewBotService(repo, timeDiffGetter)
+			tt.mockBehavior(repo, tt.chatID, tt.num)
+			zoneGetter := mock_ipgeolocation.NewMockZoneGetter(ctrl)
+			srv := NewBotService(repo, zoneGetter)
 			msg, err := srv.DeleteReminder(context.TODO(), tt.chatID, tt.msgText)
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -414,14 +461,14 @@ func TestService_DeleteReminder(t *testing.T) {
 }
 
 func TestService_SetTimezone(t *testing.T) {
-	type mockBehavior func(td *mock_ipgeolocation.MockTimeDiffGetter,
-		r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int)
+	type mockBehavior func(td *mock_ipgeolocation.MockZoneGetter,
+		r *mock_storage.MockStore, chatID int64, lat, long float64, zone string)
 	testTable := []struct {
 		name         string
 		chatID       int64
 		lat          float64
 		long         float64
-		diffhour     int
+		zone         string
 		mockBehavior mockBehavior
 		wantErr      bool
 		Error        error
@@ -431,11 +478,12 @@ func TestService_SetTimezone(t *testing.T) {
 			chatID: int64(1),
 			lat:    0.0,
 			long:   0.0,
-			mockBehavior: func(td *mock_ipgeolocation.MockTimeDiffGetter,
-				r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int) {
-				td.EXPECT().GetTimeDiff(lat, long).Return(0, nil)
+			zone:   "Europe/Berlin",
+			mockBehavior: func(td *mock_ipgeolocation.MockZoneGetter,
+				r *mock_storage.MockStore, chatID int64, lat, long float64, zone string) {
+				td.EXPECT().GetZone(lat, long).Return(zone, nil)
 				r.EXPECT().GetTimezone(context.TODO(), chatID).Return(models.ChatTimezone{}, nil)
-				r.EXPECT().UpdateTimezone(context.TODO(), chatID, lat, long, diffhour).Return(nil)
+				r.EXPECT().UpdateTimezone(context.TODO(), chatID, lat, long, zone).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -444,11 +492,12 @@ func TestService_SetTimezone(t *testing.T) {
 			chatID: int64(1),
 			lat:    0.0,
 			long:   0.0,
-			mockBehavior: func(td *mock_ipgeolocation.MockTimeDiffGetter,
-				r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int) {
-				td.EXPECT().GetTimeDiff(lat, long).Return(0, nil)
+			zone:   "Europe/Berlin",
+			mockBehavior: func(td *mock_ipgeolocation.MockZoneGetter,
+				r *mock_storage.MockStore, chatID int64, lat, long float64, zone string) {
+				td.EXPECT().GetZone(lat, long).Return(zone, nil)
 				r.EXPECT().GetTimezone(context.TODO(), chatID).Return(models.ChatTimezone{}, errors.New("notfound"))
-				r.EXPECT().AddTimezone(context.TODO(), chatID, lat, long, diffhour).Return(nil)
+				r.EXPECT().AddTimezone(context.TODO(), chatID, lat, long, zone).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -457,9 +506,10 @@ func TestService_SetTimezone(t *testing.T) {
 			chatID: int64(1),
 			lat:    0.0,
 			long:   0.0,
-			mockBehavior: func(td *mock_ipgeolocation.MockTimeDiffGetter,
-				r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int) {
-				td.EXPECT().GetTimeDiff(lat, long).Return(100, errors.New("error"))
+			zone:   "Europe/Berlin",
+			mockBehavior: func(td *mock_ipgeolocation.MockZoneGetter,
+				r *mock_storage.MockStore, chatID int64, lat, long float64, zone string) {
+				td.EXPECT().GetZone(lat, long).Return("", errors.New("error"))
 			},
 			wantErr: true,
 			Error:   errors.New("error"),
@@ -469,11 +519,12 @@ func TestService_SetTimezone(t *testing.T) {
 			chatID: int64(1),
 			lat:    0.0,
 			long:   0.0,
-			mockBehavior: func(td *mock_ipgeolocation.MockTimeDiffGetter,
-				r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int) {
-				td.EXPECT().GetTimeDiff(lat, long).Return(0, nil)
+			zone:   "Europe/Berlin",
+			mockBehavior: func(td *mock_ipgeolocation.MockZoneGetter,
+				r *mock_storage.MockStore, chatID int64, lat, long float64, zone string) {
+				td.EXPECT().GetZone(lat, long).Return(zone, nil)
 				r.EXPECT().GetTimezone(context.TODO(), chatID).Return(models.ChatTimezone{}, nil)
-				r.EXPECT().UpdateTimezone(context.TODO(), chatID, lat, long, diffhour).Return(errors.New("updating error"))
+				r.EXPECT().UpdateTimezone(context.TODO(), chatID, lat, long, zone).Return(errors.New("updating error"))
 			},
 			wantErr: true,
 			Error:   errors.New("updating error"),
@@ -483,11 +534,12 @@ func TestService_SetTimezone(t *testing.T) {
 			chatID: int64(1),
 			lat:    0.0,
 			long:   0.0,
-			mockBehavior: func(td *mock_ipgeolocation.MockTimeDiffGetter,
-				r *mock_storage.MockStore, chatID int64, lat, long float64, diffhour int) {
-				td.EXPECT().GetTimeDiff(lat, long).Return(0, nil)
+			zone:   "Europe/Berlin",
+			mockBehavior: func(td *mock_ipgeolocation.MockZoneGetter,
+				r *mock_storage.MockStore, chatID int64, lat, long float64, zone string) {
+				td.EXPECT().GetZone(lat, long).Return(zone, nil)
 				r.EXPECT().GetTimezone(context.TODO(), chatID).Return(models.ChatTimezone{}, errors.New("notfound"))
-				r.EXPECT().AddTimezone(context.TODO(), chatID, lat, long, diffhour).Return(errors.New("adding error"))
+				r.EXPECT().AddTimezone(context.TODO(), chatID, lat, long, zone).Return(errors.New("adding error"))
 			},
 			wantErr: true,
 			Error:   errors.New("adding error"),
@@ -498,8 +550,8 @@ func TestService_SetTimezone(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
-			td := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
-			tt.mockBehavior(td, repo, tt.chatID, tt.lat, tt.long, tt.diffhour)
+			td := mock_ipgeolocation.NewMockZoneGetter(ctrl)
+			tt.mockBehavior(td, repo, tt.chatID, tt.lat, tt.long, tt.zone)
 
 			srv := NewBotService(repo, td)
 			err := srv.SetTimezone(context.TODO(), tt.chatID, tt.lat, tt.long)
@@ -514,6 +566,128 @@ func TestService_SetTimezone(t *testing.T) {
 
 }
 
+func TestService_SetTimezoneByName(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, chatID int64, zone string)
+	testTable := []struct {
+		name         string
+		msgText      string
+		zone         string
+		mockBehavior mockBehavior
+		wantPrefix   string
+		wantErr      bool
+	}{
+		{
+			name:    "IANA",
+			msgText: "/settz europe/moscow",
+			zone:    "Europe/Moscow",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {
+				r.EXPECT().SetZone(context.TODO(), chatID, zone).Return(nil)
+			},
+			wantPrefix: "Хорошо, я запомнила: Europe/Moscow (сейчас там ",
+		},
+		{
+			name:    "Offset",
+			msgText: "/settz UTC+3",
+			zone:    "Etc/GMT-3",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {
+				r.EXPECT().SetZone(context.TODO(), chatID, zone).Return(nil)
+			},
+			wantPrefix: "Хорошо, я запомнила: Etc/GMT-3",
+		},
+		{
+			name:    "HalfHourOffset",
+			msgText: "/settz +5:30",
+			zone:    "UTC+05:30",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {
+				r.EXPECT().SetZone(context.TODO(), chatID, zone).Return(nil)
+			},
+			wantPrefix: "Хорошо, я запомнила: UTC+05:30",
+		},
+		{
+			name:    "City",
+			msgText: "/settz Новосибирск",
+			zone:    "Asia/Novosibirsk",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {
+				r.EXPECT().SetZone(context.TODO(), chatID, zone).Return(nil)
+			},
+			wantPrefix: "Хорошо, я запомнила: Asia/Novosibirsk",
+		},
+		{
+			name:         "Unknown",
+			msgText:      "/settz Атлантида",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {},
+			wantPrefix:   "Не знаю такого часового пояса: «Атлантида»",
+		},
+		{
+			name:         "OffsetOutOfRange",
+			msgText:      "/settz UTC+15",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {},
+			wantPrefix:   "Не знаю такого часового пояса",
+		},
+		{
+			name:         "Empty",
+			msgText:      "/settz",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {},
+			wantPrefix:   "Пожалуйста укажи часовой пояс или город!",
+		},
+		{
+			name:    "StoreError",
+			msgText: "/settz Москва",
+			zone:    "Europe/Moscow",
+			mockBehavior: func(r *mock_storage.MockStore, chatID int64, zone string) {
+				r.EXPECT().SetZone(context.TODO(), chatID, zone).Return(errors.New("update error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			chatID := int64(1)
+			tt.mockBehavior(repo, chatID, tt.zone)
+
+			srv := NewBotService(repo, nil)
+			text, err := srv.SetTimezoneByName(context.TODO(), chatID, tt.msgText)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(text, tt.wantPrefix), text)
+		})
+	}
+}
+
+func TestService_RemindMeFixedOffsetZone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	repo.EXPECT().AddReminder(context.TODO(), gomock.Any()).DoAndReturn(func(_ context.Context, reminder models.Reminder) error {
+		assert.Equal(t, 5*time.Hour+30*time.Minute, reminder.OriginalTime.Sub(reminder.Time))
+		return nil
+	})
+
+	srv := NewBotService(repo, nil)
+	_, err := srv.RemindMe(1, "/remindme 2099-01-01 10:00 проверить", &models.ChatTimezone{ChatID: 1, Zone: "UTC+05:30"})
+	assert.NoError(t, err)
+}
+
+func TestService_RemindMeSchedules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	sch := mock_service.NewMockScheduler(ctrl)
+	repo.EXPECT().AddReminder(context.TODO(), gomock.Any()).Return(nil)
+	sch.EXPECT().Schedule(time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC))
+
+	srv := NewBotService(repo, nil)
+	srv.Scheduler = sch
+	_, err := srv.RemindMe(1, "/remindme 2099-01-01 10:00 проверить", &models.ChatTimezone{ChatID: 1, Zone: "UTC"})
+	assert.NoError(t, err)
+}
+
 func TestService_GetTimezone(t *testing.T) {
 	type mockBehavior func(r *mock_storage.MockStore, chatID int64)
 	testTable := []struct {
@@ -531,12 +705,12 @@ func TestService_GetTimezone(t *testing.T) {
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64) {
 				r.EXPECT().GetTimezone(gomock.Any(), chatID).Return(
 					models.ChatTimezone{
-						Diff_hour: 0,
+						Zone: "UTC",
 					}, nil,
 				)
 			},
 			wantResp: models.ChatTimezone{
-				Diff_hour: 0,
+				Zone: "UTC",
 			},
 		},
 		{
@@ -545,7 +719,7 @@ func TestService_GetTimezone(t *testing.T) {
 			mockBehavior: func(r *mock_storage.MockStore, chatID int64) {
 				r.EXPECT().GetTimezone(gomock.Any(), chatID).Return(
 					models.ChatTimezone{
-						Diff_hour: 0,
+						Zone: "UTC",
 					}, errors.New("getting error"),
 				)
 			},
@@ -558,7 +732,7 @@ func TestService_GetTimezone(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
-			td := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
+			td := mock_ipgeolocation.NewMockZoneGetter(ctrl)
 			tt.mockBehavior(repo, tt.chatID)
 
 			srv := NewBotService(repo, td)
@@ -618,7 +792,7 @@ func TestService_DeleteTimezone(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			repo := mock_storage.NewMockStore(ctrl)
-			td := mock_ipgeolocation.NewMockTimeDiffGetter(ctrl)
+			td := mock_ipgeolocation.NewMockZoneGetter(ctrl)
 			tt.mockBehavior(repo, tt.chatID)
 
 			srv := NewBotService(repo, td)
@@ -628,3 +802,430 @@ func TestService_DeleteTimezone(t *testing.T) {
 	}
 
 }
+
+func TestService_MarkReminderAsSent(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	future := time.Now().UTC().Add(time.Hour).Truncate(time.Minute)
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		mockBehavior mockBehavior
+		wantErr      bool
+	}{
+		{
+			name:     "OneTime",
+			reminder: models.Reminder{ID: "1", ChatID: 1},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().MarkReminderAsDelivered(gomock.Any(), reminder.ID, "replica-a", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:     "Nag",
+			reminder: models.Reminder{ID: "1", ChatID: 1, Nag: &models.Nag{Interval: 15, MaxRepeats: 2}, NagCount: 1},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().ScheduleNag(gomock.Any(), reminder.ID, "replica-a", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ string, deliveredAt, next time.Time) error {
+						assert.Equal(t, 15*time.Minute, next.Sub(deliveredAt))
+						return nil
+					})
+			},
+		},
+		{
+			name: "Alert",
+			reminder: models.Reminder{
+				ID:     "1",
+				ChatID: 1,
+				Time:   future.Add(-time.Hour),
+				Nag:    &models.Nag{Interval: 15, MaxRepeats: 2},
+				Alerts: []models.Alert{{Lead: 60, At: future.Add(-time.Hour)}},
+			},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().UpdateAlerts(gomock.Any(), reminder.ID, "replica-a", gomock.Any(), future).
+					DoAndReturn(func(_ context.Context, _, _ string, alerts []models.Alert, _ time.Time) error {
+						assert.NotNil(t, alerts[0].SentAt)
+						return nil
+					})
+			},
+		},
+		{
+			name: "RecurringWithAlerts",
+			reminder: models.Reminder{
+				ID:           "1",
+				ChatID:       1,
+				Time:         future.Add(-24 * time.Hour),
+				OriginalTime: future.Add(-24 * time.Hour),
+				Recurrence:   &models.Recurrence{Frequency: "daily"},
+				Alerts:       []models.Alert{{Lead: 30, At: future.Add(-24*time.Hour - 30*time.Minute), SentAt: &future}},
+			},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "UTC"}, nil)
+				r.EXPECT().RescheduleReminder(gomock.Any(), reminder.ID, "replica-a", future.Add(-30*time.Minute), future,
+					[]models.Alert{{Lead: 30, At: future.Add(-30 * time.Minute)}}).Return(nil)
+			},
+		},
+		{
+			name:     "NagExhausted",
+			reminder: models.Reminder{ID: "1", ChatID: 1, Nag: &models.Nag{Interval: 15, MaxRepeats: 2}, NagCount: 2},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().MarkReminderAsDelivered(gomock.Any(), reminder.ID, "replica-a", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "Recurring",
+			reminder: models.Reminder{
+				ID:           "1",
+				ChatID:       1,
+				Time:         future.Add(-24 * time.Hour),
+				OriginalTime: future.Add(-24 * time.Hour).Add(3 * time.Hour),
+				Recurrence:   &models.Recurrence{Frequency: "daily"},
+			},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Etc/GMT-3"}, nil)
+				r.EXPECT().RescheduleReminder(gomock.Any(), reminder.ID, "replica-a", future, future.Add(3*time.Hour), gomock.Nil()).Return(nil)
+			},
+		},
+		{
+			name: "RescheduleError",
+			reminder: models.Reminder{
+				ID:         "1",
+				Recurrence: &models.Recurrence{Frequency: "daily"},
+			},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{}, errors.New("not found"))
+				r.EXPECT().RescheduleReminder(gomock.Any(), reminder.ID, "replica-a", gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+			srv := NewBotService(repo, mock_ipgeolocation.NewMockZoneGetter(ctrl))
+			srv.ReplicaID = "replica-a"
+			err := srv.MarkReminderAsSent(context.TODO(), tt.reminder)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestService_ReanchorReminders(t *testing.T) {
+	berlin := time.Date(2040, 7, 1, 9, 0, 0, 0, time.UTC)
+	// Напоминание на 9:00 по Москве (UTC+3) и "через час", поставленное там же
+	stored := func() []models.Reminder {
+		return []models.Reminder{
+			{ID: "1", ChatID: 1, Time: berlin.Add(-3 * time.Hour), OriginalTime: berlin},
+			{ID: "2", ChatID: 1, Time: berlin.Add(-3 * time.Hour), OriginalTime: berlin, Relative: true},
+		}
+	}
+	testTable := []struct {
+		name          string
+		keepWallClock bool
+		want          []models.Reminder
+	}{
+		{
+			name:          "WallClock",
+			keepWallClock: true,
+			want: []models.Reminder{
+				{ID: "1", ChatID: 1, Time: berlin.Add(-2 * time.Hour), OriginalTime: berlin},
+				{ID: "2", ChatID: 1, Time: berlin.Add(-3 * time.Hour), OriginalTime: berlin.Add(-time.Hour), Relative: true},
+			},
+		},
+		{
+			name:          "Instant",
+			keepWallClock: false,
+			want: []models.Reminder{
+				{ID: "1", ChatID: 1, Time: berlin.Add(-3 * time.Hour), OriginalTime: berlin.Add(-time.Hour)},
+				{ID: "2", ChatID: 1, Time: berlin.Add(-3 * time.Hour), OriginalTime: berlin.Add(-time.Hour), Relative: true},
+			},
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{ChatID: 1, Zone: "Europe/Berlin"}, nil)
+			repo.EXPECT().GetReminders(gomock.Any(), int64(1)).Return(stored(), nil)
+			repo.EXPECT().RescheduleReminders(gomock.Any(), tt.want).Return(nil)
+
+			srv := NewBotService(repo, nil)
+			text, err := srv.ReanchorReminders(context.TODO(), 1, tt.keepWallClock)
+			assert.NoError(t, err)
+			assert.Contains(t, text, "напоминаний пересчитано: 2")
+		})
+	}
+	t.Run("NoTimezone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mock_storage.NewMockStore(ctrl)
+		repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{}, errors.New("not found"))
+
+		srv := NewBotService(repo, nil)
+		_, err := srv.ReanchorReminders(context.TODO(), 1, true)
+		assert.ErrorIs(t, err, ErrUnknownTimezone)
+	})
+}
+
+func TestService_NeedsReanchor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{Zone: "Europe/Berlin"}, nil).Times(2)
+	repo.EXPECT().GetReminders(gomock.Any(), int64(1)).Return([]models.Reminder{{ID: "1"}}, nil)
+	srv := NewBotService(repo, nil)
+
+	assert.True(t, srv.NeedsReanchor(context.TODO(), 1, "Europe/Moscow"))
+	assert.False(t, srv.NeedsReanchor(context.TODO(), 1, "Europe/Berlin"))
+	assert.False(t, srv.NeedsReanchor(context.TODO(), 1, ""))
+}
+
+func TestService_AcknowledgeReminder(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	future := time.Now().UTC().Add(time.Hour).Truncate(time.Minute)
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		mockBehavior mockBehavior
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:     "OneTime",
+			reminder: models.Reminder{ID: "1", ChatID: 1, Action: "таблетка"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().AcknowledgeReminder(gomock.Any(), reminder.ChatID, reminder.ID, gomock.Any()).Return(int64(1), nil)
+			},
+			want: "таблетка\n\n✅ Готово",
+		},
+		{
+			name: "RecurringMovesToNext",
+			reminder: models.Reminder{
+				ID:           "1",
+				ChatID:       1,
+				Action:       "таблетка",
+				Time:         future.Add(-24 * time.Hour),
+				OriginalTime: future.Add(-24 * time.Hour),
+				Recurrence:   &models.Recurrence{Frequency: "daily"},
+			},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().AcknowledgeReminder(gomock.Any(), reminder.ChatID, reminder.ID, gomock.Any()).Return(int64(1), nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "UTC"}, nil)
+				r.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID, future, future, gomock.Nil()).Return(nil)
+			},
+			want: "таблетка\n\n✅ Готово",
+		},
+		{
+			name:     "AlreadyDone",
+			reminder: models.Reminder{ID: "1", ChatID: 1, Action: "таблетка"},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().AcknowledgeReminder(gomock.Any(), reminder.ChatID, reminder.ID, gomock.Any()).Return(int64(0), nil)
+			},
+			want: "таблетка\n\nЭто напоминание уже не ждет подтверждения",
+		},
+		{
+			name:     "NotFound",
+			reminder: models.Reminder{ID: "1", ChatID: 1},
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(models.Reminder{}, errors.New("not found"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+			srv := NewBotService(repo, nil)
+			text, err := srv.AcknowledgeReminder(context.TODO(), tt.reminder.ChatID, tt.reminder.ID)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, text)
+		})
+	}
+}
+
+func TestService_DeliveryText(t *testing.T) {
+	event := time.Now().UTC().Add(time.Hour + 30*time.Second).Truncate(time.Second)
+	srv := NewBotService(nil, nil)
+
+	text, alert := srv.DeliveryText(models.Reminder{
+		Action:       "рейс",
+		Time:         event.Add(-time.Hour),
+		OriginalTime: time.Date(2040, 1, 1, 10, 0, 0, 0, time.UTC),
+		Alerts:       []models.Alert{{Lead: 60, At: event.Add(-time.Hour)}},
+	})
+	assert.True(t, alert)
+	assert.Equal(t, "⏳ рейс\n\nДо события осталось 1 ч (2040-01-01 10:00)", text)
+
+	text, alert = srv.DeliveryText(models.Reminder{
+		Action: "таблетка",
+		Nag:    &models.Nag{Interval: 10, MaxRepeats: 6},
+		Time:   event,
+		Alerts: []models.Alert{{Lead: 60, At: event.Add(-time.Hour), SentAt: &event}},
+	})
+	assert.False(t, alert)
+	assert.Equal(t, "таблетка", text)
+
+	text, _ = srv.DeliveryText(models.Reminder{Action: "таблетка", Nag: &models.Nag{Interval: 10, MaxRepeats: 6}, NagCount: 2})
+	assert.Equal(t, "🔁 таблетка\n\nНапоминаю еще раз (2 из 6). Нажми «Готово», когда сделаешь", text)
+}
+
+func TestNewAlerts(t *testing.T) {
+	event := time.Date(2040, 3, 1, 10, 0, 0, 0, time.UTC)
+	now := event.Add(-2 * time.Hour)
+	alerts := newAlerts([]int{60, 24 * 60}, event, now)
+	assert.Equal(t, []models.Alert{
+		{Lead: 24 * 60, At: event.Add(-24 * time.Hour), Skipped: true},
+		{Lead: 60, At: event.Add(-time.Hour)},
+	}, alerts)
+	assert.Equal(t, event.Add(-time.Hour), nextFiring(alerts, event))
+	assert.Equal(t, event, eventTime(models.Reminder{Time: event.Add(-time.Hour), Alerts: alerts}))
+	assert.Equal(t, "за 1 ч (пропущено уже прошедших: 1)", describeAlerts(alerts))
+}
+
+func TestParseFlags(t *testing.T) {
+	testTable := []struct {
+		name       string
+		action     string
+		wantAction string
+		wantNag    *models.Nag
+		wantAlerts []int
+		wantUrgent bool
+		wantErr    bool
+	}{
+		{name: "NoFlags", action: "выпить таблетку", wantAction: "выпить таблетку"},
+		{name: "NagDefault", action: "!nag выпить таблетку", wantAction: "выпить таблетку", wantNag: &models.Nag{Interval: 10, MaxRepeats: 6}},
+		{name: "NagInterval", action: "выпить !NAG15 таблетку", wantAction: "выпить таблетку", wantNag: &models.Nag{Interval: 15, MaxRepeats: 6}},
+		{name: "NagIntervalAndRepeats", action: "выпить таблетку !nag5x3", wantAction: "выпить таблетку", wantNag: &models.Nag{Interval: 5, MaxRepeats: 3}},
+		{name: "NagZero", action: "!nag0 выпить", wantErr: true},
+		{name: "NotAFlag", action: "!nagging тест", wantAction: "!nagging тест"},
+		{name: "Urgent", action: "! выключить утюг", wantAction: "выключить утюг", wantUrgent: true},
+		{name: "ExclamationInText", action: "ура!", wantAction: "ура!"},
+		{name: "Alerts", action: "экзамен !1d !1H30m !1d", wantAction: "экзамен", wantAlerts: []int{24 * 60, 90}},
+		{name: "AlertTooFar", action: "экзамен !2w60w", wantErr: true},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			action, flags, err := parseFlags(tt.action)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAction, action)
+			assert.Equal(t, tt.wantNag, flags.Nag)
+			assert.Equal(t, tt.wantAlerts, flags.Alerts)
+			assert.Equal(t, tt.wantUrgent, flags.Urgent)
+		})
+	}
+}
+
+func TestService_RemindMeWithoutTimezone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, mock_ipgeolocation.NewMockZoneGetter(ctrl))
+
+	repo.EXPECT().AddReminder(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, reminder models.Reminder) error {
+			assert.Equal(t, "выключить плиту", reminder.Action)
+			assert.WithinDuration(t, time.Now().UTC().Add(20*time.Minute), reminder.Time, 2*time.Second)
+			return nil
+		})
+	msg, err := srv.RemindMe(1, "/remindme через 20 минут выключить плиту", nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg, "Напоминание установлено! Напомню через 20 мин"))
+
+	_, err = srv.RemindMe(1, "/remindme 12:00 обед", nil)
+	assert.Equal(t, ErrUnknownTimezone, err)
+}
+
+func TestService_RemindMeDST(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, mock_ipgeolocation.NewMockZoneGetter(ctrl))
+
+	// летом в Берлине UTC+2, зимой UTC+1
+	repo.EXPECT().AddReminder(gomock.Any(), models.Reminder{
+		ChatID:       1,
+		Action:       "test",
+		Time:         time.Date(2040, 7, 1, 10, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2040, 7, 1, 12, 0, 0, 0, time.UTC),
+	}).Return(nil)
+	repo.EXPECT().AddReminder(gomock.Any(), models.Reminder{
+		ChatID:       1,
+		Action:       "test",
+		Time:         time.Date(2040, 12, 1, 11, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2040, 12, 1, 12, 0, 0, 0, time.UTC),
+	}).Return(nil)
+	tz := &models.ChatTimezone{ChatID: 1, Zone: "Europe/Berlin"}
+	_, err := srv.RemindMe(1, "/remindme 2040-07-01 12:00 test", tz)
+	assert.NoError(t, err)
+	_, err = srv.RemindMe(1, "/remindme 2040-12-01 12:00 test", tz)
+	assert.NoError(t, err)
+
+	_, err = srv.RemindMe(1, "/remindme 2040-12-01 12:00 test", &models.ChatTimezone{ChatID: 1})
+	assert.Error(t, err)
+}
+
+func TestService_MigrateTimezones(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	zoneGetter := mock_ipgeolocation.NewMockZoneGetter(ctrl)
+	srv := NewBotService(repo, zoneGetter)
+
+	repo.EXPECT().GetLegacyTimezones(gomock.Any()).Return([]models.LegacyTimezone{
+		{ChatID: 1, Latitude: 52.5, Longitude: 13.4, DiffHour: 2},
+		{ChatID: 2, Latitude: 0, Longitude: 0, DiffHour: 5},
+	}, nil)
+	zoneGetter.EXPECT().GetZone(52.5, 13.4).Return("Europe/Berlin", nil)
+	zoneGetter.EXPECT().GetZone(0.0, 0.0).Return("", errors.New("no api key"))
+	repo.EXPECT().UpdateTimezone(gomock.Any(), int64(1), 52.5, 13.4, "Europe/Berlin").Return(nil)
+	repo.EXPECT().UpdateTimezone(gomock.Any(), int64(2), 0.0, 0.0, "Etc/GMT-5").Return(nil)
+
+	migrated, err := srv.MigrateTimezones(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+}
+
+func TestService_MigrateReminderNumbers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+
+	repo.EXPECT().GetUnnumberedReminders(gomock.Any()).Return([]models.Reminder{
+		{ID: "a", ChatID: 1},
+		{ID: "b", ChatID: 1},
+		{ID: "c", ChatID: 2},
+	}, nil)
+	gomock.InOrder(
+		repo.EXPECT().NextReminderNum(gomock.Any(), int64(1)).Return(4, nil),
+		repo.EXPECT().SetReminderNum(gomock.Any(), "a", 4).Return(nil),
+		repo.EXPECT().NextReminderNum(gomock.Any(), int64(1)).Return(5, nil),
+		repo.EXPECT().SetReminderNum(gomock.Any(), "b", 5).Return(nil),
+		repo.EXPECT().NextReminderNum(gomock.Any(), int64(2)).Return(1, nil),
+		repo.EXPECT().SetReminderNum(gomock.Any(), "c", 1).Return(nil),
+	)
+
+	migrated, err := srv.MigrateReminderNumbers(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, migrated)
+}
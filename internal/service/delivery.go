@@ -0,0 +1,127 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+)
+
+// deliveryLease - на сколько реплика захватывает напоминание для отправки. Если она упадет, не успев
+// отправить, напоминание подхватит другая реплика, когда аренда истечет.
+const deliveryLease = 2 * time.Minute
+
+// ClaimDueReminder захватывает для этой реплики следующее наступившее напоминание.
+// false - отправлять больше нечего: все наступившие напоминания уже отправляются.
+func (s *BotSevice) ClaimDueReminder(ctx context.Context) (models.Reminder, bool, error) {
+	now := time.Now().UTC()
+	return s.Store.ClaimDueReminder(ctx, s.ReplicaID, now, now.Add(deliveryLease))
+}
+
+// ExtendLease продлевает аренду захваченного напоминания, если в очереди отправки оно простоит wait
+// и обычной аренды может не хватить. Иначе аренда истечет раньше отправки, и напоминание захватят
+// и отправят еще раз. false - аренда уже потеряна, и отправлять напоминание не нужно.
+func (s *BotSevice) ExtendLease(ctx context.Context, reminder models.Reminder, wait time.Duration) (bool, error) {
+	if wait < deliveryLease/2 {
+		return true, nil
+	}
+	return s.Store.ExtendLease(ctx, reminder.ID, s.ReplicaID, time.Now().UTC().Add(wait+deliveryLease))
+}
+
+// ReleaseReminder возвращает захваченное, но не отправленное напоминание: его сразу подхватит
+// другая реплика, не дожидаясь конца аренды, которая при длинной очереди растягивается надолго.
+func (s *BotSevice) ReleaseReminder(ctx context.Context, reminder models.Reminder) error {
+	return s.Store.ReleaseLease(ctx, reminder.ID, s.ReplicaID)
+}
+
+// StillDue проверяет перед самой отправкой, что захваченное напоминание все еще ждет ее: пока оно стояло
+// в очереди, пользователь мог нажать "Готово" под прошлой отправкой или удалить напоминание, а если
+// аренда истекла, напоминание могла перехватить и отправить другая реплика.
+func (s *BotSevice) StillDue(ctx context.Context, reminder models.Reminder) (bool, error) {
+	current, err := s.Store.GetReminder(ctx, reminder.ChatID, reminder.ID)
+	if err != nil {
+		return false, err
+	}
+	return current.IsActive && current.ClaimedBy == s.ReplicaID, nil
+}
+
+// newReplicaID придумывает имя реплики бота: хост и процесс для логов и случайный хвост,
+// чтобы перезапущенный процесс с тем же PID не считался прежним.
+func newReplicaID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
+
+// Повторы неудачных отправок: через 30 секунд, минуту, две и так далее, но не реже чем раз в полчаса.
+// После maxDeliveryAttempts неудач подряд напоминание бросается и попадает в отчет администратора.
+const (
+	maxDeliveryAttempts = 5
+	firstRetryDelay     = 30 * time.Second
+	maxRetryDelay       = 30 * time.Minute
+	failedReportLimit   = 20
+)
+
+// DeliveryFailed записывает неудачную отправку напоминания и назначает повтор. retryAfter - сколько
+// просил подождать Telegram, если он ответил 429; такая попытка не считается неудачной, ведь с самим
+// напоминанием все в порядке.
+func (s *BotSevice) DeliveryFailed(ctx context.Context, reminder models.Reminder, sendErr string, retryAfter time.Duration) error {
+	now := time.Now().UTC()
+	attempts := reminder.Attempts
+	if retryAfter == 0 {
+		attempts++
+	}
+	if attempts >= maxDeliveryAttempts {
+		return s.Store.MarkReminderAsFailed(ctx, reminder.ID, s.ReplicaID, attempts, sendErr, now)
+	}
+	retryAt := now.Add(max(retryDelay(attempts), retryAfter))
+	if err := s.Store.RecordDeliveryFailure(ctx, reminder.ID, s.ReplicaID, attempts, sendErr, retryAt); err != nil {
+		return err
+	}
+	s.schedule(retryAt)
+	return nil
+}
+
+// retryDelay - пауза перед следующей попыткой после attempts неудач подряд.
+func retryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// FailedReport - отчет администратора о напоминаниях, которые не удалось отправить.
+func (s *BotSevice) FailedReport(ctx context.Context, chatID int64) (string, error) {
+	if !slices.Contains(s.Admins, chatID) {
+		return "Эта команда только для администратора бота", nil
+	}
+	reminders, err := s.Store.GetFailedReminders(ctx, failedReportLimit)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if len(reminders) == 0 {
+		return "Недоставленных напоминаний нет", nil
+	}
+	report := fmt.Sprintf("Недоставленные напоминания (последние %d):\n", len(reminders))
+	for _, reminder := range reminders {
+		failedAt := ""
+		if reminder.FailedAt != nil {
+			failedAt = reminder.FailedAt.UTC().Format("2006-01-02 15:04") + " UTC, "
+		}
+		report += fmt.Sprintf("\n• %sчат %d, №%d «%s»\n  попыток: %d, ошибка: %s\n",
+			failedAt, reminder.ChatID, reminder.Num, reminder.Action, reminder.Attempts, reminder.LastError)
+	}
+	return report, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_service "JillBot/internal/service/mocks"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_ClaimDueReminder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+	srv.ReplicaID = "replica-a"
+
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1, Action: "зарядка"}
+	repo.EXPECT().ClaimDueReminder(gomock.Any(), "replica-a", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, now, leaseUntil time.Time) (models.Reminder, bool, error) {
+			assert.WithinDuration(t, time.Now(), now, time.Second)
+			assert.Equal(t, deliveryLease, leaseUntil.Sub(now))
+			return reminder, true, nil
+		})
+
+	got, ok, err := srv.ClaimDueReminder(context.TODO())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, reminder, got)
+}
+
+func TestService_ExtendLease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+	srv.ReplicaID = "replica-a"
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1}
+
+	// Короткая очередь укладывается в аренду, полученную при захвате
+	ok, err := srv.ExtendLease(context.TODO(), reminder, 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// 300 напоминаний одного чата уходят по одному в секунду - аренду нужно продлить на время ожидания
+	wait := 300 * time.Second
+	repo.EXPECT().ExtendLease(gomock.Any(), reminder.ID, "replica-a", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ string, until time.Time) (bool, error) {
+			assert.WithinDuration(t, time.Now().Add(wait+deliveryLease), until, time.Second)
+			return true, nil
+		})
+	ok, err = srv.ExtendLease(context.TODO(), reminder, wait)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	repo.EXPECT().ExtendLease(gomock.Any(), reminder.ID, "replica-a", gomock.Any()).Return(false, nil)
+	ok, err = srv.ExtendLease(context.TODO(), reminder, wait)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestService_ReleaseReminder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+	srv.ReplicaID = "replica-a"
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1}
+
+	repo.EXPECT().ReleaseLease(gomock.Any(), reminder.ID, "replica-a").Return(nil)
+	assert.NoError(t, srv.ReleaseReminder(context.TODO(), reminder))
+}
+
+func TestService_StillDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+	srv.ReplicaID = "replica-a"
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1, IsActive: true, ClaimedBy: "replica-a"}
+
+	repo.EXPECT().GetReminder(gomock.Any(), int64(1), reminder.ID).Return(reminder, nil)
+	due, err := srv.StillDue(context.TODO(), reminder)
+	assert.NoError(t, err)
+	assert.True(t, due)
+
+	// Пока повтор ждал в очереди, пользователь нажал "Готово"
+	acked := reminder
+	acked.IsActive = false
+	repo.EXPECT().GetReminder(gomock.Any(), int64(1), reminder.ID).Return(acked, nil)
+	due, err = srv.StillDue(context.TODO(), reminder)
+	assert.NoError(t, err)
+	assert.False(t, due)
+
+	// Аренда истекла, пока напоминание стояло в очереди, и его захватила другая реплика
+	stolen := reminder
+	stolen.ClaimedBy = "replica-b"
+	repo.EXPECT().GetReminder(gomock.Any(), int64(1), reminder.ID).Return(stolen, nil)
+	due, err = srv.StillDue(context.TODO(), reminder)
+	assert.NoError(t, err)
+	assert.False(t, due)
+
+	repo.EXPECT().GetReminder(gomock.Any(), int64(1), reminder.ID).Return(models.Reminder{}, errors.New("connection refused"))
+	_, err = srv.StillDue(context.TODO(), reminder)
+	assert.Error(t, err)
+}
+
+func TestNewReplicaID(t *testing.T) {
+	// Два процесса на одном хосте с одинаковым PID (например, после перезапуска контейнера) различаются
+	assert.NotEqual(t, newReplicaID(), newReplicaID())
+	assert.NotEmpty(t, NewBotService(nil, nil).ReplicaID)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, retryDelay(1))
+	assert.Equal(t, time.Minute, retryDelay(2))
+	assert.Equal(t, 4*time.Minute, retryDelay(4))
+	assert.Equal(t, maxRetryDelay, retryDelay(10))
+	assert.Equal(t, maxRetryDelay, retryDelay(100))
+}
+
+func TestService_DeliveryFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	id := "507f1f77bcf86cd799439011"
+
+	t.Run("Retry", func(t *testing.T) {
+		repo := mock_storage.NewMockStore(ctrl)
+		sch := mock_service.NewMockScheduler(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.ReplicaID = "replica-a"
+		srv.Scheduler = sch
+		sch.EXPECT().Schedule(gomock.Any())
+		repo.EXPECT().RecordDeliveryFailure(gomock.Any(), id, "replica-a", 2, "502 Bad Gateway", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ string, _ int, _ string, retryAt time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(time.Minute), retryAt, time.Second)
+				return nil
+			})
+
+		err := srv.DeliveryFailed(context.TODO(), models.Reminder{ID: id, Attempts: 1}, "502 Bad Gateway", 0)
+		assert.NoError(t, err)
+	})
+	t.Run("RetryAfterIsNotAnAttempt", func(t *testing.T) {
+		repo := mock_storage.NewMockStore(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.ReplicaID = "replica-a"
+		repo.EXPECT().RecordDeliveryFailure(gomock.Any(), id, "replica-a", 4, "429 Too Many Requests", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ string, _ int, _ string, retryAt time.Time) error {
+				// Telegram просит подождать дольше, чем пауза после четырех неудач
+				assert.WithinDuration(t, time.Now().Add(10*time.Minute), retryAt, time.Second)
+				return nil
+			})
+
+		err := srv.DeliveryFailed(context.TODO(), models.Reminder{ID: id, Attempts: 4}, "429 Too Many Requests", 10*time.Minute)
+		assert.NoError(t, err)
+	})
+	t.Run("GiveUp", func(t *testing.T) {
+		repo := mock_storage.NewMockStore(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.ReplicaID = "replica-a"
+		// Брошенное напоминание больше не планируется: Schedule у мока не ожидается
+		srv.Scheduler = mock_service.NewMockScheduler(ctrl)
+		repo.EXPECT().MarkReminderAsFailed(gomock.Any(), id, "replica-a", maxDeliveryAttempts, "403 Forbidden", gomock.Any()).Return(nil)
+
+		err := srv.DeliveryFailed(context.TODO(), models.Reminder{ID: id, Attempts: maxDeliveryAttempts - 1}, "403 Forbidden", 0)
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_FailedReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	failedAt := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+
+	t.Run("NotAdmin", func(t *testing.T) {
+		srv := NewBotService(mock_storage.NewMockStore(ctrl), nil)
+		srv.Admins = []int64{42}
+
+		report, err := srv.FailedReport(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Эта команда только для администратора бота", report)
+	})
+	t.Run("OK", func(t *testing.T) {
+		repo := mock_storage.NewMockStore(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.Admins = []int64{42}
+		repo.EXPECT().GetFailedReminders(gomock.Any(), failedReportLimit).Return([]models.Reminder{
+			{ChatID: 7, Num: 3, Action: "зарядка", Attempts: 5, LastError: "403 Forbidden", FailedAt: &failedAt},
+		}, nil)
+
+		report, err := srv.FailedReport(context.TODO(), 42)
+		assert.NoError(t, err)
+		assert.Equal(t, "Недоставленные напоминания (последние 1):\n"+
+			"\n• 2025-01-16 05:00 UTC, чат 7, №3 «зарядка»\n  попыток: 5, ошибка: 403 Forbidden\n", report)
+	})
+	t.Run("Empty", func(t *testing.T) {
+		repo := mock_storage.NewMockStore(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.Admins = []int64{42}
+		repo.EXPECT().GetFailedReminders(gomock.Any(), failedReportLimit).Return(nil, nil)
+
+		report, err := srv.FailedReport(context.TODO(), 42)
+		assert.NoError(t, err)
+		assert.Equal(t, "Недоставленных напоминаний нет", report)
+	})
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultDigestMinute - сводка по умолчанию приходит в 08:00.
+	defaultDigestMinute = 8 * 60
+	// digestWindow - насколько сводка может опоздать. Если бот был выключен дольше или чат сменил
+	// часовой пояс, сводка за этот день не отправляется, а переносится на следующее утро.
+	digestWindow = 2 * time.Hour
+	// DigestDayLayout - формат дня сводки в данных кнопки "Перенести день".
+	DigestDayLayout = "2006-01-02"
+)
+
+const digestUsage = "Сводка на день: /digest on - присылать в 08:00, /digest 07:30 - в свое время, /digest off - выключить"
+
+// DigestCommand включает, выключает и показывает утреннюю сводку: /digest, /digest on, /digest 07:30, /digest off.
+func (s *BotSevice) DigestCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	args := strings.Fields(strings.TrimPrefix(msgText, "/digest"))
+	if len(args) > 1 {
+		return digestUsage, nil
+	}
+	settings, err := s.Store.GetSettings(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	digest := models.DigestSettings{Minute: defaultDigestMinute}
+	if settings.Digest != nil {
+		digest = *settings.Digest
+	}
+	if len(args) == 0 {
+		if !digest.Enabled {
+			return "Сводка на день выключена. Включить: /digest on (в 08:00) или /digest 07:30", nil
+		}
+		return fmt.Sprintf("Сводка на день приходит в %s. Поменять время: /digest 07:30, выключить: /digest off",
+			formatMinute(digest.Minute)), nil
+	}
+
+	switch arg := strings.ToLower(args[0]); arg {
+	case "off", "выкл":
+		digest.Enabled = false
+	case "on", "вкл":
+		digest.Enabled = true
+	default:
+		clock, err := time.Parse("15:04", arg)
+		if err != nil {
+			return digestUsage, nil
+		}
+		digest.Enabled = true
+		digest.Minute = clock.Hour()*60 + clock.Minute()
+	}
+	loc, known := s.chatZone(ctx, chatID)
+	digest.Next = nextLocalMinute(digest.Minute, loc, time.Now().UTC())
+	settings.Digest = &digest
+	if err := s.Store.SaveSettings(ctx, settings); err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if !digest.Enabled {
+		return "Хорошо, сводку больше не присылаю", nil
+	}
+	message := fmt.Sprintf("Хорошо, буду присылать сводку на день в %s", formatMinute(digest.Minute))
+	if !known {
+		message += " по UTC. Чтобы сводка приходила по местному времени, укажи часовой пояс: /setlocation или /settz"
+	}
+	return message + ". Если на день ничего не запланировано, сводки не будет", nil
+}
+
+// GetDueDigests готовит сводки, которые пора отправить, и сразу переносит следующую сводку каждого
+// чата на завтра. Чаты, у которых на сегодня ничего нет, сводку не получают. Если запущено несколько
+// реплик бота, сводку готовит та, что первой ее перенесла.
+func (s *BotSevice) GetDueDigests(ctx context.Context) ([]models.Digest, error) {
+	now := time.Now().UTC()
+	due, err := s.Store.GetDueDigests(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	var digests []models.Digest
+	for _, settings := range due {
+		loc, _ := s.chatZone(ctx, settings.ChatID)
+		minute := settings.Digest.Minute
+		advanced, err := s.Store.AdvanceDigest(ctx, settings.ChatID, settings.Digest.Next, nextLocalMinute(minute, loc, now))
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if !advanced {
+			continue
+		}
+		local := now.In(loc)
+		scheduled := time.Date(local.Year(), local.Month(), local.Day(), minute/60, minute%60, 0, 0, loc)
+		if late := now.Sub(scheduled); late < 0 || late >= digestWindow {
+			continue
+		}
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+		reminders, err := s.dayReminders(ctx, settings.ChatID, day)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if len(reminders) == 0 {
+			continue
+		}
+		digests = append(digests, models.Digest{
+			ChatID: settings.ChatID,
+			Day:    day.Format(DigestDayLayout),
+			Text:   digestText(day, reminders),
+		})
+	}
+	return digests, nil
+}
+
+// PostponeDay переносит на сутки все напоминания чата на день day из сводки.
+func (s *BotSevice) PostponeDay(ctx context.Context, chatID int64, day string) (string, error) {
+	start, err := time.Parse(DigestDayLayout, day)
+	if err != nil {
+		return "", err
+	}
+	reminders, err := s.dayReminders(ctx, chatID, start)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if len(reminders) == 0 {
+		return "На этот день напоминаний уже нет", nil
+	}
+	postponed := 0
+	for _, reminder := range reminders {
+		if _, err := s.postpone(ctx, reminder, 24*time.Hour); err != nil {
+			log.Println(err)
+			continue
+		}
+		postponed++
+	}
+	if postponed == 0 {
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	message := fmt.Sprintf("Перенесла на завтра напоминаний: %d", postponed)
+	if postponed < len(reminders) {
+		message += fmt.Sprintf(" (не получилось: %d)", len(reminders)-postponed)
+	}
+	return message, nil
+}
+
+// dayReminders возвращает активные напоминания чата на день day (полночь по местному времени, записанная как UTC)
+// в порядке времени события.
+func (s *BotSevice) dayReminders(ctx context.Context, chatID int64, day time.Time) ([]models.Reminder, error) {
+	filter := models.ReminderFilter{From: day, To: day.AddDate(0, 0, 1)}
+	reminders, err := s.Store.GetRemindersPage(ctx, chatID, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	// utc_time может указывать на предупреждение заранее, поэтому порядок - по времени самого события
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].OriginalTime.Before(reminders[j].OriginalTime)
+	})
+	return reminders, nil
+}
+
+// digestText печатает сводку, сгруппированную по часам.
+func digestText(day time.Time, reminders []models.Reminder) string {
+	text := fmt.Sprintf("☀️ План на сегодня, %s (напоминаний: %d):\n", day.Format("2006-01-02"), len(reminders))
+	hour := -1
+	for _, reminder := range reminders {
+		if h := reminder.OriginalTime.Hour(); h != hour {
+			hour = h
+			text += fmt.Sprintf("\n🕘 %02d:00\n", hour)
+		}
+		text += fmt.Sprintf("• %s №%d %s\n", reminder.OriginalTime.Format("15:04"), reminder.Num, reminder.Action)
+	}
+	return text
+}
+
+// nextLocalMinute возвращает ближайший после now момент, когда на часах чата minute минут от полуночи.
+func nextLocalMinute(minute int, loc *time.Location, now time.Time) time.Time {
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), minute/60, minute%60, 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, minute/60, minute%60, 0, 0, loc)
+	}
+	return next.UTC()
+}
+
+// formatMinute печатает минуты от полуночи как время на часах.
+func formatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
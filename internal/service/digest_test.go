@@ -0,0 +1,225 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextLocalMinute(t *testing.T) {
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	// 04:30 UTC - 07:30 по Москве, сводка в 08:00 еще сегодня
+	now := time.Date(2025, 1, 16, 4, 30, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC), nextLocalMinute(8*60, moscow, now))
+	// В 08:00 по Москве сводка уже ушла - следующая завтра
+	now = time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 17, 5, 0, 0, 0, time.UTC), nextLocalMinute(8*60, moscow, now))
+	// Переход на летнее время: в Берлине 30 марта 08:00 это уже 06:00 UTC
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	now = time.Date(2025, 3, 29, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 30, 6, 0, 0, 0, time.UTC), nextLocalMinute(8*60, berlin, now))
+}
+
+func TestService_DigestCommand(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore)
+	moscow := models.ChatTimezone{ChatID: 1, Zone: "Europe/Moscow"}
+	enabled := models.ChatSettings{ChatID: 1, Digest: &models.DigestSettings{Enabled: true, Minute: 7*60 + 30}}
+	testTable := []struct {
+		name         string
+		msgText      string
+		mockBehavior mockBehavior
+		wantSaved    *models.DigestSettings
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:    "StatusOff",
+			msgText: "/digest",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			want: "Сводка на день выключена. Включить: /digest on (в 08:00) или /digest 07:30",
+		},
+		{
+			name:    "StatusOn",
+			msgText: "/digest",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(enabled, nil)
+			},
+			want: "Сводка на день приходит в 07:30. Поменять время: /digest 07:30, выключить: /digest off",
+		},
+		{
+			name:    "OnDefault",
+			msgText: "/digest on",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(moscow, nil)
+			},
+			wantSaved: &models.DigestSettings{Enabled: true, Minute: 8 * 60},
+			want:      "Хорошо, буду присылать сводку на день в 08:00. Если на день ничего не запланировано, сводки не будет",
+		},
+		{
+			name:    "Time",
+			msgText: "/digest 7:05",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{}, errors.New("not found"))
+			},
+			wantSaved: &models.DigestSettings{Enabled: true, Minute: 7*60 + 5},
+			want: "Хорошо, буду присылать сводку на день в 07:05 по UTC. Чтобы сводка приходила по местному времени, " +
+				"укажи часовой пояс: /setlocation или /settz. Если на день ничего не запланировано, сводки не будет",
+		},
+		{
+			name:    "Off",
+			msgText: "/digest off",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(enabled, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(moscow, nil)
+			},
+			wantSaved: &models.DigestSettings{Minute: 7*60 + 30},
+			want:      "Хорошо, сводку больше не присылаю",
+		},
+		{
+			name:    "BadTime",
+			msgText: "/digest 25:00",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			want: digestUsage,
+		},
+		{
+			name:    "SettingsError",
+			msgText: "/digest on",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{}, errors.New("find error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo)
+			if tt.wantSaved != nil {
+				repo.EXPECT().SaveSettings(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, settings models.ChatSettings) error {
+					assert.Equal(t, int64(1), settings.ChatID)
+					assert.True(t, settings.Digest.Next.After(time.Now()))
+					saved := *settings.Digest
+					saved.Next = time.Time{}
+					assert.Equal(t, *tt.wantSaved, saved)
+					return nil
+				})
+			}
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.DigestCommand(context.TODO(), 1, tt.msgText)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetDueDigests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+
+	now := time.Now().UTC()
+	minute := now.Hour()*60 + now.Minute()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	at := func(hour, min int) time.Time {
+		return today.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	due := []models.ChatSettings{
+		{ChatID: 1, Digest: &models.DigestSettings{Enabled: true, Minute: minute}},
+		{ChatID: 2, Digest: &models.DigestSettings{Enabled: true, Minute: minute}},
+		// Сводка должна была прийти три часа назад - бот был выключен, сегодня ее уже не шлем
+		{ChatID: 3, Digest: &models.DigestSettings{Enabled: true, Minute: (minute + 21*60) % (24 * 60)}},
+	}
+	taken := models.ChatSettings{ChatID: 4, Digest: &models.DigestSettings{Enabled: true, Minute: minute}}
+	repo.EXPECT().GetDueDigests(gomock.Any(), gomock.Any()).Return(append(due, taken), nil)
+	repo.EXPECT().GetTimezone(gomock.Any(), gomock.Any()).Return(models.ChatTimezone{Zone: "UTC"}, nil).Times(4)
+	for _, settings := range due {
+		repo.EXPECT().AdvanceDigest(gomock.Any(), settings.ChatID, settings.Digest.Next, gomock.Any()).Return(true, nil)
+	}
+	// Сводку чата 4 уже перенесла и отправит другая реплика
+	repo.EXPECT().AdvanceDigest(gomock.Any(), int64(4), gomock.Any(), gomock.Any()).Return(false, nil)
+	day := models.ReminderFilter{From: today, To: today.AddDate(0, 0, 1)}
+	repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), day, 0, 0).Return([]models.Reminder{
+		{Num: 2, Action: "врач", OriginalTime: at(14, 0)},
+		{Num: 5, Action: "позвонить", OriginalTime: at(9, 40)},
+		{Num: 3, Action: "зарядка", OriginalTime: at(9, 15)},
+	}, nil)
+	repo.EXPECT().GetRemindersPage(gomock.Any(), int64(2), day, 0, 0).Return(nil, nil)
+
+	srv := NewBotService(repo, nil)
+	digests, err := srv.GetDueDigests(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Digest{{
+		ChatID: 1,
+		Day:    today.Format(DigestDayLayout),
+		Text: "☀️ План на сегодня, " + today.Format("2006-01-02") + " (напоминаний: 3):\n" +
+			"\n🕘 09:00\n• 09:15 №3 зарядка\n• 09:40 №5 позвонить\n" +
+			"\n🕘 14:00\n• 14:00 №2 врач\n",
+	}}, digests)
+}
+
+func TestService_PostponeDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+
+	day := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	reminders := []models.Reminder{
+		{ID: "507f1f77bcf86cd799439011", ChatID: 1, Num: 1, Action: "зарядка", IsActive: true,
+			Time: day.Add(9 * time.Hour), OriginalTime: day.Add(9 * time.Hour)},
+		{ID: "507f1f77bcf86cd799439012", ChatID: 1, Num: 2, Action: "врач", IsActive: true,
+			Time: day.Add(14 * time.Hour), OriginalTime: day.Add(14 * time.Hour)},
+	}
+	repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{From: day, To: day.AddDate(0, 0, 1)}, 0, 0).Return(reminders, nil)
+	repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{Zone: "UTC"}, nil).Times(2)
+	for _, reminder := range reminders {
+		moved := reminder
+		moved.Time = reminder.Time.AddDate(0, 0, 1)
+		moved.OriginalTime = reminder.OriginalTime.AddDate(0, 0, 1)
+		repo.EXPECT().UpdateReminder(gomock.Any(), moved).Return(int64(1), nil)
+	}
+
+	srv := NewBotService(repo, nil)
+	got, err := srv.PostponeDay(context.TODO(), 1, "2099-01-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "Перенесла на завтра напоминаний: 2", got)
+}
+
+func TestService_PostponeDayDaily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+
+	day := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	daily := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1, Num: 1, Action: "зарядка", IsActive: true,
+		Time: day.Add(9 * time.Hour), OriginalTime: day.Add(9 * time.Hour), Recurrence: &models.Recurrence{Frequency: "daily"}}
+	repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{From: day, To: day.AddDate(0, 0, 1)}, 0, 0).
+		Return([]models.Reminder{daily}, nil)
+	repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{Zone: "UTC"}, nil).Times(2)
+	// Завтрашний повтор и есть перенесенный раз: копия не создается, иначе завтра пришло бы два напоминания
+	tomorrow := day.AddDate(0, 0, 1).Add(9 * time.Hour)
+	repo.EXPECT().ReactivateReminder(gomock.Any(), daily.ID, tomorrow, tomorrow, gomock.Nil()).Return(nil)
+
+	srv := NewBotService(repo, nil)
+	got, err := srv.PostponeDay(context.TODO(), 1, "2099-01-01")
+	assert.NoError(t, err)
+	assert.Equal(t, "Перенесла на завтра напоминаний: 1", got)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"JillBot/pkg/timeparse"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const editUsage = "Пожалуйста укажи номер напоминания из /list и что поменять!\n" +
+	"Новое время: /edit 3 завтра в 10:00\n" +
+	"Новый текст: /edit 3 купить хлеб и молоко\n" +
+	"Или все сразу: /edit 3 в пятницу 18:00 бар"
+
+// EditReminder меняет время и/или текст активного напоминания с номером из /list. Новое время записывается
+// так же, как в /remindme; если после номера нет выражения времени, меняется только текст. Флаги вроде !nag и !1h
+// заменяют прежние настройки, остальные настройки и правило повтора сохраняются.
+// tz равен nil, если часовой пояс чата неизвестен.
+func (s *BotSevice) EditReminder(ctx context.Context, chatID int64, msgText string, tz *models.ChatTimezone) (string, error) {
+	parts := strings.Fields(strings.TrimPrefix(msgText, "/edit"))
+	if len(parts) < 2 {
+		return editUsage, nil
+	}
+	num, ok := parseNum(parts[0])
+	if !ok {
+		return editUsage, nil
+	}
+	rest := strings.Join(parts[1:], " ")
+	var loc *time.Location
+	if tz != nil {
+		var err error
+		loc, err = chatLocation(*tz)
+		if err != nil {
+			return "", err
+		}
+	}
+	old, err := s.Store.GetReminderByNum(ctx, chatID, num)
+	if err != nil || !old.IsActive {
+		if err != nil {
+			log.Println(err)
+		}
+		return "Напоминание не было найдено", nil
+	}
+
+	now := time.Now().UTC()
+	text := rest
+	parsed, err := timeparse.ParsePrefix(rest, now, loc)
+	timeChanged := err == nil
+	if timeChanged {
+		text = parsed.Action
+	} else if timeparse.StartsWithTime(rest) {
+		return "", err
+	}
+	action, flags, err := parseFlags(text)
+	if err != nil {
+		return "", err
+	}
+
+	reminder := old
+	if action != "" {
+		reminder.Action = action
+		reminder.Tags = parseTags(action)
+	}
+	if flags.Nag != nil {
+		reminder.Nag = flags.Nag
+	}
+	if flags.Urgent {
+		reminder.Urgent = true
+	}
+	event := eventTime(old)
+	if timeChanged {
+		event = parsed.When
+		reminder.OriginalTime = wallClock(parsed.When)
+		reminder.Relative = parsed.Relative
+		if parsed.Recurrence != nil {
+			reminder.Recurrence = recurrenceToModel(parsed.Recurrence)
+		}
+		// Новое время - новое срабатывание: прежние отправки и повторы больше не считаются
+		reminder.DeliveredAt = nil
+		reminder.DeferredFrom = nil
+		reminder.NagCount = 0
+	}
+	leads := alertLeads(old.Alerts)
+	if len(flags.Alerts) > 0 {
+		leads = flags.Alerts
+	}
+	if timeChanged || len(flags.Alerts) > 0 {
+		reminder.Alerts = newAlerts(leads, event, now)
+		reminder.Time = nextFiring(reminder.Alerts, event)
+	}
+
+	changes, err := s.Store.UpdateReminder(ctx, reminder)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if changes == 0 {
+		return "Напоминание не было найдено", nil
+	}
+	s.schedule(reminder.Time)
+	suffix := ""
+	if loc == nil {
+		suffix = " UTC"
+	}
+	return fmt.Sprintf("Напоминание изменено:\n- %s\n+ %s", describeEdit(old, suffix), describeEdit(reminder, suffix)), nil
+}
+
+// describeEdit печатает напоминание одной строкой для ответа на /edit.
+func describeEdit(reminder models.Reminder, suffix string) string {
+	line := fmt.Sprintf("%s%s %s", reminder.OriginalTime.Format("2006-01-02 15:04"), suffix, reminder.Action)
+	if reminder.Recurrence != nil {
+		line += fmt.Sprintf(" (повтор: %s)", recurrenceFromModel(reminder.Recurrence))
+	}
+	if reminder.Urgent {
+		line += " (срочное)"
+	}
+	if reminder.Nag != nil {
+		line += fmt.Sprintf(" (повторять каждые %d мин до %d раз)", reminder.Nag.Interval, reminder.Nag.MaxRepeats)
+	}
+	if len(reminder.Alerts) > 0 {
+		line += " (предупредить " + describeAlerts(reminder.Alerts) + ")"
+	}
+	return line
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_EditReminder(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	reminder := models.Reminder{
+		ID:           "507f1f77bcf86cd799439011",
+		ChatID:       1,
+		Action:       "позвонить",
+		Time:         time.Date(2099, 1, 1, 9, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC),
+		IsActive:     true,
+	}
+	withAlert := reminder
+	withAlert.Alerts = []models.Alert{{Lead: 60, At: time.Date(2099, 1, 1, 8, 0, 0, 0, time.UTC)}}
+	withAlert.Time = withAlert.Alerts[0].At
+	moscow := &models.ChatTimezone{Zone: "Europe/Moscow"}
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		msgText      string
+		tz           *models.ChatTimezone
+		mockBehavior mockBehavior
+		want         string
+		wantErr      bool
+		wantErrIs    error
+	}{
+		{
+			name:     "Time",
+			reminder: reminder,
+			msgText:  "/edit 3 2099-01-02 10:00",
+			tz:       moscow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(reminder, nil)
+				updated := reminder
+				updated.Time = time.Date(2099, 1, 2, 7, 0, 0, 0, time.UTC)
+				updated.OriginalTime = time.Date(2099, 1, 2, 10, 0, 0, 0, time.UTC)
+				r.EXPECT().UpdateReminder(gomock.Any(), updated).Return(int64(1), nil)
+			},
+			want: "Напоминание изменено:\n- 2099-01-01 12:00 позвонить\n+ 2099-01-02 10:00 позвонить",
+		},
+		{
+			name:     "Text",
+			reminder: reminder,
+			msgText:  "/edit 3 позвонить маме",
+			tz:       moscow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(reminder, nil)
+				updated := reminder
+				updated.Action = "позвонить маме"
+				r.EXPECT().UpdateReminder(gomock.Any(), updated).Return(int64(1), nil)
+			},
+			want: "Напоминание изменено:\n- 2099-01-01 12:00 позвонить\n+ 2099-01-01 12:00 позвонить маме",
+		},
+		{
+			name:     "TimeAndTextWithoutTimezone",
+			reminder: reminder,
+			msgText:  "/edit 3 2099-01-02 10:00 позвонить маме",
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(reminder, nil)
+			},
+			wantErr:   true,
+			wantErrIs: ErrUnknownTimezone,
+		},
+		{
+			name:     "TimeMovesAlerts",
+			reminder: withAlert,
+			msgText:  "/edit 3 2099-01-02 10:00 позвонить маме",
+			tz:       moscow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(reminder, nil)
+				r.EXPECT().UpdateReminder(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, updated models.Reminder) (int64, error) {
+						assert.Equal(t, "позвонить маме", updated.Action)
+						assert.Equal(t, time.Date(2099, 1, 2, 6, 0, 0, 0, time.UTC), updated.Time)
+						assert.Equal(t, 60, updated.Alerts[0].Lead)
+						return 1, nil
+					})
+			},
+			want: "Напоминание изменено:\n- 2099-01-01 12:00 позвонить (предупредить за 1 ч)\n+ 2099-01-02 10:00 позвонить маме (предупредить за 1 ч)",
+		},
+		{
+			name:     "BadTime",
+			reminder: reminder,
+			msgText:  "/edit 3 25:00",
+			tz:       moscow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(reminder, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:     "NotFound",
+			reminder: reminder,
+			msgText:  "/edit 3 позвонить маме",
+			tz:       moscow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, 3).Return(models.Reminder{}, errors.New("not found"))
+			},
+			want: "Напоминание не было найдено",
+		},
+		{
+			name:         "Usage",
+			reminder:     reminder,
+			msgText:      "/edit 3",
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {},
+			want:         editUsage,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.EditReminder(context.TODO(), tt.reminder.ChatID, tt.msgText, tt.tz)
+			if tt.wantErr {
+				assert.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
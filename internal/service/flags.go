@@ -0,0 +1,142 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Настройки повторов по умолчанию для флага !nag.
+const (
+	defaultNagInterval   = 10
+	defaultNagMaxRepeats = 6
+)
+
+// nagFlag - "!nag", "!nag15" (каждые 15 минут) или "!nag15x4" (каждые 15 минут, не больше 4 раз).
+var nagFlag = regexp.MustCompile(`^!nag(?:(\d+)(?:x(\d+))?)?$`)
+
+// alertFlag - предупреждение заранее: "!1d", "!1h", "!30m", "!1h30m", "!1w".
+var alertFlag = regexp.MustCompile(`^!(?:\d+[wdhm])+$`)
+var alertPart = regexp.MustCompile(`(\d+)([wdhm])`)
+
+// shortDuration - длительность в том же виде, что и у флагов предупреждений, но без "!": "1h", "30m", "1h30m".
+var shortDuration = regexp.MustCompile(`^(?:\d+[wdhm])+$`)
+
+// hashtag - тег в тексте напоминания: "#финансы", "#проект_1".
+var hashtag = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+
+// maxAlertLead - предупреждать заранее можно не больше чем за год.
+const maxAlertLead = 365 * 24 * 60
+
+// reminderFlags - настройки напоминания, заданные флагами вида !nag в тексте команды.
+type reminderFlags struct {
+	Nag *models.Nag
+	// Alerts - за сколько минут до события предупредить.
+	Alerts []int
+	// Urgent - флаг "!": напоминание приходит и в тихие часы.
+	Urgent bool
+}
+
+// parseFlags вынимает флаги из текста действия и возвращает действие без них.
+func parseFlags(action string) (string, reminderFlags, error) {
+	var flags reminderFlags
+	var words []string
+	for _, word := range strings.Fields(action) {
+		if word == "!" {
+			flags.Urgent = true
+			continue
+		}
+		if m := nagFlag.FindStringSubmatch(strings.ToLower(word)); m != nil {
+			nag, err := parseNag(m)
+			if err != nil {
+				return "", flags, err
+			}
+			flags.Nag = nag
+			continue
+		}
+		if alertFlag.MatchString(strings.ToLower(word)) {
+			lead, err := parseAlertLead(strings.ToLower(word))
+			if err != nil {
+				return "", flags, err
+			}
+			if !slices.Contains(flags.Alerts, lead) {
+				flags.Alerts = append(flags.Alerts, lead)
+			}
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), flags, nil
+}
+
+func parseNag(m []string) (*models.Nag, error) {
+	nag := &models.Nag{Interval: defaultNagInterval, MaxRepeats: defaultNagMaxRepeats}
+	if m[1] != "" {
+		nag.Interval, _ = strconv.Atoi(m[1])
+	}
+	if m[2] != "" {
+		nag.MaxRepeats, _ = strconv.Atoi(m[2])
+	}
+	if nag.Interval < 1 || nag.Interval > 24*60 || nag.MaxRepeats < 1 || nag.MaxRepeats > 100 {
+		return nil, fmt.Errorf("не поняла «%s»: повторять можно раз в 1-1440 минут и не больше 100 раз, например !nag15x4", m[0])
+	}
+	return nag, nil
+}
+
+// parseAlertLead переводит "!1h30m" в минуты.
+func parseAlertLead(flag string) (int, error) {
+	lead := durationMinutes(flag)
+	if lead < 1 || lead > maxAlertLead {
+		return 0, fmt.Errorf("не поняла «%s»: предупредить заранее можно за время от минуты до года, например !1d или !1h30m", flag)
+	}
+	return lead, nil
+}
+
+// durationMinutes переводит "1h30m" или "!1h30m" в минуты. Все, что больше года, превращается в maxAlertLead+1.
+func durationMinutes(s string) int {
+	units := map[string]int{"w": 7 * 24 * 60, "d": 24 * 60, "h": 60, "m": 1}
+	minutes := 0
+	for _, m := range alertPart.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n > maxAlertLead {
+			return maxAlertLead + 1
+		}
+		minutes += n * units[m[2]]
+	}
+	return min(minutes, maxAlertLead+1)
+}
+
+// parseShortDuration разбирает "1h", "30m", "1d", "1h30m" - не меньше минуты и не больше года.
+func parseShortDuration(s string) (time.Duration, bool) {
+	s = strings.ToLower(s)
+	if !shortDuration.MatchString(s) {
+		return 0, false
+	}
+	minutes := durationMinutes(s)
+	if minutes < 1 || minutes > maxAlertLead {
+		return 0, false
+	}
+	return time.Duration(minutes) * time.Minute, true
+}
+
+// parseTags возвращает теги из текста напоминания, в нижнем регистре и без повторов. Сами теги
+// остаются в тексте.
+func parseTags(action string) []string {
+	var tags []string
+	for _, tag := range hashtag.FindAllString(action, -1) {
+		tag = normalizeTag(tag)
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// normalizeTag приводит "#Финансы" и "финансы" к одному виду: "финансы".
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// listPostpone - на сколько откладывает напоминание кнопка в списке.
+const listPostpone = time.Hour
+
+// PostponeReminder откладывает ближайшее срабатывание напоминания из списка на час.
+func (s *BotSevice) PostponeReminder(ctx context.Context, chatID int64, num int) (string, error) {
+	reminder, err := s.listedReminder(ctx, chatID, num)
+	if err != nil {
+		return "", err
+	}
+	when, err := s.postpone(ctx, reminder, listPostpone)
+	if errors.Is(err, errReminderNotFound) {
+		return "", err
+	}
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	return fmt.Sprintf("Напоминание №%d отложено до %s", reminder.Num, when.Format("2006-01-02 15:04")), nil
+}
+
+// postpone откладывает ближайшее срабатывание напоминания на d и возвращает новое время по часам чата.
+// Разовое напоминание просто переезжает, а у повторяющегося переносится только ближайший раз:
+// на новое время ставится разовая копия, а само напоминание переходит к следующему повтору после
+// отложенного. Если ближайший раз отложен ровно на следующий повтор, копия не нужна.
+// Напоминание, которое уже сработало и ждет "Готово", откладывается от текущего момента.
+func (s *BotSevice) postpone(ctx context.Context, reminder models.Reminder, d time.Duration) (time.Time, error) {
+	loc := s.reminderLocation(ctx, reminder)
+	now := time.Now().UTC()
+	event := eventTime(reminder)
+	when := event
+	if when.Before(now) {
+		when = now
+	}
+	when = when.Add(d).Truncate(time.Minute)
+	local := when.In(loc)
+	if reminder.Recurrence != nil {
+		next := recurrenceFromModel(reminder.Recurrence).Next(fromWallClock(reminder.OriginalTime, loc), event)
+		if when.Equal(next) {
+			return local, s.rescheduleRecurring(ctx, reminder, event)
+		}
+		err := s.Store.AddReminder(ctx, models.Reminder{
+			ChatID:       reminder.ChatID,
+			Action:       reminder.Action,
+			Time:         when.UTC(),
+			OriginalTime: wallClock(local),
+			Nag:          reminder.Nag,
+			Urgent:       reminder.Urgent,
+			Tags:         reminder.Tags,
+		})
+		if err != nil {
+			return local, err
+		}
+		s.schedule(when)
+		// Повторы, которые попали внутрь переноса, не нужны: отложенный раз их заменяет
+		return local, s.rescheduleRecurring(ctx, reminder, when)
+	}
+	reminder.Alerts = newAlerts(alertLeads(reminder.Alerts), when, now)
+	reminder.Time = nextFiring(reminder.Alerts, when)
+	reminder.OriginalTime = wallClock(local)
+	reminder.DeliveredAt = nil
+	reminder.DeferredFrom = nil
+	reminder.NagCount = 0
+	changes, err := s.Store.UpdateReminder(ctx, reminder)
+	if err == nil && changes == 0 {
+		return local, errReminderNotFound
+	}
+	if err == nil {
+		s.schedule(reminder.Time)
+	}
+	return local, err
+}
+
+// ReminderDetails описывает напоминание из списка со всеми настройками.
+func (s *BotSevice) ReminderDetails(ctx context.Context, chatID int64, num int) (string, error) {
+	reminder, err := s.listedReminder(ctx, chatID, num)
+	if err != nil {
+		return "", err
+	}
+	text := fmt.Sprintf("Напоминание №%d\n⏰ Время: %s\n📋 Действие: %s",
+		reminder.Num, reminder.OriginalTime.Format("2006-01-02 15:04"), reminder.Action)
+	if reminder.Recurrence != nil {
+		text += fmt.Sprintf("\n🔁 Повтор: %s", recurrenceFromModel(reminder.Recurrence))
+	}
+	if reminder.Nag != nil {
+		text += fmt.Sprintf("\n🔔 Повторять каждые %d мин, пока не нажмешь «Готово» (до %d раз)",
+			reminder.Nag.Interval, reminder.Nag.MaxRepeats)
+	}
+	if len(reminder.Alerts) > 0 {
+		text += "\n⏳ Предупредить: " + describeAlerts(reminder.Alerts)
+	}
+	if reminder.Urgent {
+		text += "\n❗ Срочное: приходит и в тихие часы"
+	}
+	if reminder.DeferredFrom != nil {
+		loc := s.reminderLocation(ctx, reminder)
+		text += fmt.Sprintf("\n🌙 Отложено тихими часами до %s", reminder.Time.In(loc).Format("2006-01-02 15:04"))
+	}
+	if len(reminder.Tags) > 0 {
+		text += "\n🏷 Теги: #" + strings.Join(reminder.Tags, " #")
+	}
+	if reminder.DeliveredAt != nil {
+		loc := s.reminderLocation(ctx, reminder)
+		text += fmt.Sprintf("\n📨 Отправлено в %s, ждет подтверждения", reminder.DeliveredAt.In(loc).Format("2006-01-02 15:04"))
+	}
+	return text, nil
+}
+
+// listedReminder находит активное напоминание по номеру из списка.
+func (s *BotSevice) listedReminder(ctx context.Context, chatID int64, num int) (models.Reminder, error) {
+	reminder, err := s.Store.GetReminderByNum(ctx, chatID, num)
+	if err != nil {
+		log.Println(err)
+		return reminder, errReminderNotFound
+	}
+	if !reminder.IsActive {
+		return reminder, errReminderNotFound
+	}
+	return reminder, nil
+}
+
+// ListFilterMark начинает строку с фильтром в сообщении со списком. По этой строке фильтр
+// восстанавливается, когда пользователь листает страницы.
+const ListFilterMark = "🔎 "
+
+var (
+	listMonthRe = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	listDayRe   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+const listFilterUsage = "Можно так: /list today, /list week, /list overdue, /list 2025-01, /list 2025-01-15, /list #тег или /find текст"
+
+// ErrBadFilter возвращается, если в команде /list или /find неправильный фильтр.
+var ErrBadFilter = errors.New("не поняла фильтр")
+
+// ListFilterFromText находит фильтр в тексте сообщения со списком или с напоминанием из него.
+func ListFilterFromText(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if filter, ok := strings.CutPrefix(line, ListFilterMark); ok {
+			return normalizeListFilter(filter)
+		}
+	}
+	return ""
+}
+
+// normalizeListFilter приводит команду с фильтром к одному виду: без имени бота и лишних пробелов.
+// Команда /list без аргументов - это весь список, фильтр для нее пустой.
+func normalizeListFilter(filter string) string {
+	fields := strings.Fields(filter)
+	if len(fields) == 0 {
+		return ""
+	}
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	if command == "/list" && len(fields) == 1 {
+		return ""
+	}
+	return strings.Join(append([]string{command}, fields[1:]...), " ")
+}
+
+// listConditions переводит команду с фильтром в условия выборки. Границы дней и месяцев считаются
+// по часам чата; если часовой пояс неизвестен - по UTC.
+func (s *BotSevice) listConditions(ctx context.Context, chatID int64, filter string, now time.Time) (models.ReminderFilter, error) {
+	var conditions models.ReminderFilter
+	if filter == "" {
+		return conditions, nil
+	}
+	command, arg, _ := strings.Cut(filter, " ")
+	if command == "/find" {
+		if arg == "" {
+			return conditions, fmt.Errorf("%w: напиши, что искать, например: /find врач", ErrBadFilter)
+		}
+		conditions.Query = arg
+		return conditions, nil
+	}
+	loc, _ := s.chatZone(ctx, chatID)
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	switch arg = strings.ToLower(arg); {
+	case strings.HasPrefix(arg, "#"):
+		if hashtag.FindString(arg) != arg {
+			return conditions, fmt.Errorf("%w «%s». %s", ErrBadFilter, arg, listFilterUsage)
+		}
+		conditions.Tag = normalizeTag(arg)
+	case arg == "today" || arg == "сегодня":
+		conditions.From, conditions.To = today, today.AddDate(0, 0, 1)
+	case arg == "week" || arg == "неделя":
+		// Неделя начинается с понедельника
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		conditions.From, conditions.To = monday, monday.AddDate(0, 0, 7)
+	case arg == "overdue" || arg == "просроченные":
+		conditions.DueBefore = now.UTC()
+	case listMonthRe.MatchString(arg):
+		month, err := time.Parse("2006-01", arg)
+		if err != nil {
+			return conditions, fmt.Errorf("%w «%s». %s", ErrBadFilter, arg, listFilterUsage)
+		}
+		conditions.From, conditions.To = month, month.AddDate(0, 1, 0)
+	case listDayRe.MatchString(arg):
+		day, err := time.Parse("2006-01-02", arg)
+		if err != nil {
+			return conditions, fmt.Errorf("%w «%s». %s", ErrBadFilter, arg, listFilterUsage)
+		}
+		conditions.From, conditions.To = day, day.AddDate(0, 0, 1)
+	default:
+		return conditions, fmt.Errorf("%w «%s». %s", ErrBadFilter, arg, listFilterUsage)
+	}
+	return conditions, nil
+}
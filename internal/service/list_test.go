@@ -0,0 +1,179 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_PostponeReminder(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	oneTime := models.Reminder{
+		ID:           "507f1f77bcf86cd799439011",
+		ChatID:       1,
+		Num:          3,
+		Action:       "позвонить",
+		Time:         time.Date(2099, 1, 1, 9, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC),
+		IsActive:     true,
+	}
+	recurring := oneTime
+	recurring.Recurrence = &models.Recurrence{Frequency: "daily"}
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		mockBehavior mockBehavior
+		want         string
+		wantErr      error
+	}{
+		{
+			name:     "OneTime",
+			reminder: oneTime,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, reminder.Num).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+				moved := reminder
+				moved.Time = time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC)
+				moved.OriginalTime = time.Date(2099, 1, 1, 13, 0, 0, 0, time.UTC)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved).Return(int64(1), nil)
+			},
+			want: "Напоминание №3 отложено до 2099-01-01 13:00",
+		},
+		{
+			name:     "RecurringMovesOnlyThisTime",
+			reminder: recurring,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, reminder.Num).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil).Times(2)
+				r.EXPECT().AddReminder(gomock.Any(), models.Reminder{
+					ChatID:       reminder.ChatID,
+					Action:       reminder.Action,
+					Time:         time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC),
+					OriginalTime: time.Date(2099, 1, 1, 13, 0, 0, 0, time.UTC),
+				}).Return(nil)
+				r.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID,
+					time.Date(2099, 1, 2, 9, 0, 0, 0, time.UTC), time.Date(2099, 1, 2, 12, 0, 0, 0, time.UTC), gomock.Nil()).Return(nil)
+			},
+			want: "Напоминание №3 отложено до 2099-01-01 13:00",
+		},
+		{
+			name:     "NotFound",
+			reminder: oneTime,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, reminder.Num).Return(models.Reminder{}, errors.New("not found"))
+			},
+			wantErr: errReminderNotFound,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.PostponeReminder(context.TODO(), tt.reminder.ChatID, tt.reminder.Num)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_ReminderDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	reminder := models.Reminder{
+		ChatID:       1,
+		Num:          3,
+		Action:       "зарядка",
+		Time:         time.Date(2099, 1, 1, 6, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2099, 1, 1, 9, 0, 0, 0, time.UTC),
+		IsActive:     true,
+		Recurrence:   &models.Recurrence{Frequency: "daily"},
+		Nag:          &models.Nag{Interval: 10, MaxRepeats: 6},
+	}
+	repo.EXPECT().GetReminderByNum(gomock.Any(), reminder.ChatID, reminder.Num).Return(reminder, nil)
+
+	srv := NewBotService(repo, nil)
+	text, err := srv.ReminderDetails(context.TODO(), reminder.ChatID, reminder.Num)
+	assert.NoError(t, err)
+	assert.Equal(t, "Напоминание №3\n⏰ Время: 2099-01-01 09:00\n📋 Действие: зарядка\n"+
+		"🔁 Повтор: каждый день\n🔔 Повторять каждые 10 мин, пока не нажмешь «Готово» (до 6 раз)", text)
+}
+
+func TestService_listConditions(t *testing.T) {
+	// Среда, 15 января 2025, 23:30 по Москве - в UTC еще 20:30
+	now := time.Date(2025, 1, 15, 20, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	testTable := []struct {
+		filter  string
+		want    models.ReminderFilter
+		wantErr bool
+	}{
+		{filter: "", want: models.ReminderFilter{}},
+		{filter: "/list today", want: models.ReminderFilter{From: day(15), To: day(16)}},
+		{filter: "/list сегодня", want: models.ReminderFilter{From: day(15), To: day(16)}},
+		{filter: "/list week", want: models.ReminderFilter{From: day(13), To: day(20)}},
+		{filter: "/list overdue", want: models.ReminderFilter{DueBefore: now}},
+		{filter: "/list 2025-02", want: models.ReminderFilter{From: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		{filter: "/list 2025-01-20", want: models.ReminderFilter{From: day(20), To: day(21)}},
+		{filter: "/find Врач и анализы", want: models.ReminderFilter{Query: "Врач и анализы"}},
+		{filter: "/list #Работа", want: models.ReminderFilter{Tag: "работа"}},
+		{filter: "/find", wantErr: true},
+		{filter: "/list #", wantErr: true},
+		{filter: "/list 2025-13", wantErr: true},
+		{filter: "/list завтра", wantErr: true},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.filter, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			repo.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil).AnyTimes()
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.listConditions(context.TODO(), 1, tt.filter, now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrBadFilter)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetListByPageFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	filter := models.ReminderFilter{Query: "врач"}
+	repo.EXPECT().CountReminders(gomock.Any(), int64(1), filter).Return(1, nil)
+	repo.EXPECT().GetRemindersPage(gomock.Any(), int64(1), filter, 0, 5).Return([]models.Reminder{
+		{Num: 4, Action: "Врач", OriginalTime: time.Date(2025, 1, 20, 10, 0, 0, 0, time.UTC)},
+	}, nil)
+	repo.EXPECT().CountReminders(gomock.Any(), int64(1), models.ReminderFilter{Query: "зубной"}).Return(0, nil)
+
+	srv := NewBotService(repo, nil)
+	list, err := srv.GetListByPage(1, 0, "/find@JillBot  врач")
+	assert.NoError(t, err)
+	assert.Equal(t, "/find врач", list.Filter)
+	assert.Equal(t, "🔎 /find врач\nНайдено 1 напоминаний:\n№4\n⏰ Время: 2025-01-20 10:00:00\n📋 Действие: Врач\n\nСтраница №1 из 1", list.Text)
+	assert.Equal(t, "/find врач", ListFilterFromText(list.Text))
+
+	list, err = srv.GetListByPage(1, 0, "/find зубной")
+	assert.NoError(t, err)
+	assert.Equal(t, "🔎 /find зубной\nНичего не нашлось", list.Text)
+}
@@ -8,6 +8,7 @@ import (
 	models "JillBot/internal/models"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,53 @@ func (m *MockBotSrv) EXPECT() *MockBotSrvMockRecorder {
 	return m.recorder
 }
 
+// AcknowledgeReminder mocks base method.
+func (m *MockBotSrv) AcknowledgeReminder(ctx context.Context, chatID int64, id string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeReminder", ctx, chatID, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcknowledgeReminder indicates an expected call of AcknowledgeReminder.
+func (mr *MockBotSrvMockRecorder) AcknowledgeReminder(ctx, chatID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeReminder", reflect.TypeOf((*MockBotSrv)(nil).AcknowledgeReminder), ctx, chatID, id)
+}
+
+// CheckQuietHours mocks base method.
+func (m *MockBotSrv) CheckQuietHours(ctx context.Context, reminder models.Reminder) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckQuietHours", ctx, reminder)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CheckQuietHours indicates an expected call of CheckQuietHours.
+func (mr *MockBotSrvMockRecorder) CheckQuietHours(ctx, reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckQuietHours", reflect.TypeOf((*MockBotSrv)(nil).CheckQuietHours), ctx, reminder)
+}
+
+// ClaimDueReminder mocks base method.
+func (m *MockBotSrv) ClaimDueReminder(ctx context.Context) (models.Reminder, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueReminder", ctx)
+	ret0, _ := ret[0].(models.Reminder)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimDueReminder indicates an expected call of ClaimDueReminder.
+func (mr *MockBotSrvMockRecorder) ClaimDueReminder(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueReminder", reflect.TypeOf((*MockBotSrv)(nil).ClaimDueReminder), ctx)
+}
+
 // DeleteReminder mocks base method.
 func (m *MockBotSrv) DeleteReminder(ctx context.Context, chatID int64, msgText string) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +98,21 @@ func (mr *MockBotSrvMockRecorder) DeleteReminder(ctx, chatID, msgText interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminder", reflect.TypeOf((*MockBotSrv)(nil).DeleteReminder), ctx, chatID, msgText)
 }
 
+// DeleteReminderByNum mocks base method.
+func (m *MockBotSrv) DeleteReminderByNum(ctx context.Context, chatID int64, num int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReminderByNum", ctx, chatID, num)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReminderByNum indicates an expected call of DeleteReminderByNum.
+func (mr *MockBotSrvMockRecorder) DeleteReminderByNum(ctx, chatID, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReminderByNum", reflect.TypeOf((*MockBotSrv)(nil).DeleteReminderByNum), ctx, chatID, num)
+}
+
 // DeleteTimezone mocks base method.
 func (m *MockBotSrv) DeleteTimezone(ctx context.Context, chatID int64) bool {
 	m.ctrl.T.Helper()
@@ -64,19 +127,123 @@ func (mr *MockBotSrvMockRecorder) DeleteTimezone(ctx, chatID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTimezone", reflect.TypeOf((*MockBotSrv)(nil).DeleteTimezone), ctx, chatID)
 }
 
-// GetListByPage mocks base method.
-func (m *MockBotSrv) GetListByPage(chatID int64, page int) (string, error) {
+// DeliveryFailed mocks base method.
+func (m *MockBotSrv) DeliveryFailed(ctx context.Context, reminder models.Reminder, sendErr string, retryAfter time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliveryFailed", ctx, reminder, sendErr, retryAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeliveryFailed indicates an expected call of DeliveryFailed.
+func (mr *MockBotSrvMockRecorder) DeliveryFailed(ctx, reminder, sendErr, retryAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliveryFailed", reflect.TypeOf((*MockBotSrv)(nil).DeliveryFailed), ctx, reminder, sendErr, retryAfter)
+}
+
+// DeliveryText mocks base method.
+func (m *MockBotSrv) DeliveryText(reminder models.Reminder) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliveryText", reminder)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// DeliveryText indicates an expected call of DeliveryText.
+func (mr *MockBotSrvMockRecorder) DeliveryText(reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliveryText", reflect.TypeOf((*MockBotSrv)(nil).DeliveryText), reminder)
+}
+
+// DigestCommand mocks base method.
+func (m *MockBotSrv) DigestCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DigestCommand", ctx, chatID, msgText)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DigestCommand indicates an expected call of DigestCommand.
+func (mr *MockBotSrvMockRecorder) DigestCommand(ctx, chatID, msgText interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DigestCommand", reflect.TypeOf((*MockBotSrv)(nil).DigestCommand), ctx, chatID, msgText)
+}
+
+// EditReminder mocks base method.
+func (m *MockBotSrv) EditReminder(ctx context.Context, chatID int64, msgText string, tz *models.ChatTimezone) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditReminder", ctx, chatID, msgText, tz)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditReminder indicates an expected call of EditReminder.
+func (mr *MockBotSrvMockRecorder) EditReminder(ctx, chatID, msgText, tz interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditReminder", reflect.TypeOf((*MockBotSrv)(nil).EditReminder), ctx, chatID, msgText, tz)
+}
+
+// ExtendLease mocks base method.
+func (m *MockBotSrv) ExtendLease(ctx context.Context, reminder models.Reminder, wait time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendLease", ctx, reminder, wait)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendLease indicates an expected call of ExtendLease.
+func (mr *MockBotSrvMockRecorder) ExtendLease(ctx, reminder, wait interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendLease", reflect.TypeOf((*MockBotSrv)(nil).ExtendLease), ctx, reminder, wait)
+}
+
+// FailedReport mocks base method.
+func (m *MockBotSrv) FailedReport(ctx context.Context, chatID int64) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetListByPage", chatID, page)
+	ret := m.ctrl.Call(m, "FailedReport", ctx, chatID)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// FailedReport indicates an expected call of FailedReport.
+func (mr *MockBotSrvMockRecorder) FailedReport(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailedReport", reflect.TypeOf((*MockBotSrv)(nil).FailedReport), ctx, chatID)
+}
+
+// GetDueDigests mocks base method.
+func (m *MockBotSrv) GetDueDigests(ctx context.Context) ([]models.Digest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDigests", ctx)
+	ret0, _ := ret[0].([]models.Digest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDigests indicates an expected call of GetDueDigests.
+func (mr *MockBotSrvMockRecorder) GetDueDigests(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDigests", reflect.TypeOf((*MockBotSrv)(nil).GetDueDigests), ctx)
+}
+
+// GetListByPage mocks base method.
+func (m *MockBotSrv) GetListByPage(chatID int64, page int, filter string) (models.ListPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListByPage", chatID, page, filter)
+	ret0, _ := ret[0].(models.ListPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // GetListByPage indicates an expected call of GetListByPage.
-func (mr *MockBotSrvMockRecorder) GetListByPage(chatID, page interface{}) *gomock.Call {
+func (mr *MockBotSrvMockRecorder) GetListByPage(chatID, page, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByPage", reflect.TypeOf((*MockBotSrv)(nil).GetListByPage), chatID, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListByPage", reflect.TypeOf((*MockBotSrv)(nil).GetListByPage), chatID, page, filter)
 }
 
 // GetTimezone mocks base method.
@@ -95,32 +262,18 @@ func (mr *MockBotSrvMockRecorder) GetTimezone(ctx, chatID interface{}) *gomock.C
 }
 
 // GetUpcomingReminders mocks base method.
-func (m *MockBotSrv) GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error) {
+func (m *MockBotSrv) GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUpcomingReminders", ctx)
+	ret := m.ctrl.Call(m, "GetUpcomingReminders", ctx, until)
 	ret0, _ := ret[0].([]models.Reminder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUpcomingReminders indicates an expected call of GetUpcomingReminders.
-func (mr *MockBotSrvMockRecorder) GetUpcomingReminders(ctx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingReminders", reflect.TypeOf((*MockBotSrv)(nil).GetUpcomingReminders), ctx)
-}
-
-// GetUserPage mocks base method.
-func (m *MockBotSrv) GetUserPage(ctx context.Context, chatID int64) int {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserPage", ctx, chatID)
-	ret0, _ := ret[0].(int)
-	return ret0
-}
-
-// GetUserPage indicates an expected call of GetUserPage.
-func (mr *MockBotSrvMockRecorder) GetUserPage(ctx, chatID interface{}) *gomock.Call {
+func (mr *MockBotSrvMockRecorder) GetUpcomingReminders(ctx, until interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPage", reflect.TypeOf((*MockBotSrv)(nil).GetUserPage), ctx, chatID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingReminders", reflect.TypeOf((*MockBotSrv)(nil).GetUpcomingReminders), ctx, until)
 }
 
 // HelpCommand mocks base method.
@@ -139,21 +292,109 @@ func (mr *MockBotSrvMockRecorder) HelpCommand() *gomock.Call {
 }
 
 // MarkReminderAsSent mocks base method.
-func (m *MockBotSrv) MarkReminderAsSent(ctx context.Context, chatID int64, id string) error {
+func (m *MockBotSrv) MarkReminderAsSent(ctx context.Context, reminder models.Reminder) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkReminderAsSent", ctx, chatID, id)
+	ret := m.ctrl.Call(m, "MarkReminderAsSent", ctx, reminder)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // MarkReminderAsSent indicates an expected call of MarkReminderAsSent.
-func (mr *MockBotSrvMockRecorder) MarkReminderAsSent(ctx, chatID, id interface{}) *gomock.Call {
+func (mr *MockBotSrvMockRecorder) MarkReminderAsSent(ctx, reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsSent", reflect.TypeOf((*MockBotSrv)(nil).MarkReminderAsSent), ctx, reminder)
+}
+
+// NeedsReanchor mocks base method.
+func (m *MockBotSrv) NeedsReanchor(ctx context.Context, chatID int64, oldZone string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsReanchor", ctx, chatID, oldZone)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsReanchor indicates an expected call of NeedsReanchor.
+func (mr *MockBotSrvMockRecorder) NeedsReanchor(ctx, chatID, oldZone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsReanchor", reflect.TypeOf((*MockBotSrv)(nil).NeedsReanchor), ctx, chatID, oldZone)
+}
+
+// PostponeDay mocks base method.
+func (m *MockBotSrv) PostponeDay(ctx context.Context, chatID int64, day string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeDay", ctx, chatID, day)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostponeDay indicates an expected call of PostponeDay.
+func (mr *MockBotSrvMockRecorder) PostponeDay(ctx, chatID, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeDay", reflect.TypeOf((*MockBotSrv)(nil).PostponeDay), ctx, chatID, day)
+}
+
+// PostponeReminder mocks base method.
+func (m *MockBotSrv) PostponeReminder(ctx context.Context, chatID int64, num int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostponeReminder", ctx, chatID, num)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostponeReminder indicates an expected call of PostponeReminder.
+func (mr *MockBotSrvMockRecorder) PostponeReminder(ctx, chatID, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostponeReminder", reflect.TypeOf((*MockBotSrv)(nil).PostponeReminder), ctx, chatID, num)
+}
+
+// QuietCommand mocks base method.
+func (m *MockBotSrv) QuietCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuietCommand", ctx, chatID, msgText)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuietCommand indicates an expected call of QuietCommand.
+func (mr *MockBotSrvMockRecorder) QuietCommand(ctx, chatID, msgText interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsSent", reflect.TypeOf((*MockBotSrv)(nil).MarkReminderAsSent), ctx, chatID, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuietCommand", reflect.TypeOf((*MockBotSrv)(nil).QuietCommand), ctx, chatID, msgText)
+}
+
+// ReanchorReminders mocks base method.
+func (m *MockBotSrv) ReanchorReminders(ctx context.Context, chatID int64, keepWallClock bool) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReanchorReminders", ctx, chatID, keepWallClock)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReanchorReminders indicates an expected call of ReanchorReminders.
+func (mr *MockBotSrvMockRecorder) ReanchorReminders(ctx, chatID, keepWallClock interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReanchorReminders", reflect.TypeOf((*MockBotSrv)(nil).ReanchorReminders), ctx, chatID, keepWallClock)
+}
+
+// ReleaseReminder mocks base method.
+func (m *MockBotSrv) ReleaseReminder(ctx context.Context, reminder models.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseReminder", ctx, reminder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseReminder indicates an expected call of ReleaseReminder.
+func (mr *MockBotSrvMockRecorder) ReleaseReminder(ctx, reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseReminder", reflect.TypeOf((*MockBotSrv)(nil).ReleaseReminder), ctx, reminder)
 }
 
 // RemindMe mocks base method.
-func (m *MockBotSrv) RemindMe(chatID int64, msgText string, tz models.ChatTimezone) (string, error) {
+func (m *MockBotSrv) RemindMe(chatID int64, msgText string, tz *models.ChatTimezone) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "RemindMe", chatID, msgText, tz)
 	ret0, _ := ret[0].(string)
@@ -167,6 +408,52 @@ func (mr *MockBotSrvMockRecorder) RemindMe(chatID, msgText, tz interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemindMe", reflect.TypeOf((*MockBotSrv)(nil).RemindMe), chatID, msgText, tz)
 }
 
+// ReminderDetails mocks base method.
+func (m *MockBotSrv) ReminderDetails(ctx context.Context, chatID int64, num int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReminderDetails", ctx, chatID, num)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReminderDetails indicates an expected call of ReminderDetails.
+func (mr *MockBotSrvMockRecorder) ReminderDetails(ctx, chatID, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReminderDetails", reflect.TypeOf((*MockBotSrv)(nil).ReminderDetails), ctx, chatID, num)
+}
+
+// ResumeChat mocks base method.
+func (m *MockBotSrv) ResumeChat(ctx context.Context, chatID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeChat", ctx, chatID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeChat indicates an expected call of ResumeChat.
+func (mr *MockBotSrvMockRecorder) ResumeChat(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeChat", reflect.TypeOf((*MockBotSrv)(nil).ResumeChat), ctx, chatID)
+}
+
+// ResumeOffer mocks base method.
+func (m *MockBotSrv) ResumeOffer(ctx context.Context, chatID int64) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeOffer", ctx, chatID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResumeOffer indicates an expected call of ResumeOffer.
+func (mr *MockBotSrvMockRecorder) ResumeOffer(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeOffer", reflect.TypeOf((*MockBotSrv)(nil).ResumeOffer), ctx, chatID)
+}
+
 // SetTimezone mocks base method.
 func (m *MockBotSrv) SetTimezone(ctx context.Context, chatID int64, lat, long float64) error {
 	m.ctrl.T.Helper()
@@ -181,16 +468,141 @@ func (mr *MockBotSrvMockRecorder) SetTimezone(ctx, chatID, lat, long interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimezone", reflect.TypeOf((*MockBotSrv)(nil).SetTimezone), ctx, chatID, lat, long)
 }
 
-// SetUserPage mocks base method.
-func (m *MockBotSrv) SetUserPage(ctx context.Context, chatID int64, page int) error {
+// SetTimezoneByName mocks base method.
+func (m *MockBotSrv) SetTimezoneByName(ctx context.Context, chatID int64, msgText string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTimezoneByName", ctx, chatID, msgText)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTimezoneByName indicates an expected call of SetTimezoneByName.
+func (mr *MockBotSrvMockRecorder) SetTimezoneByName(ctx, chatID, msgText interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimezoneByName", reflect.TypeOf((*MockBotSrv)(nil).SetTimezoneByName), ctx, chatID, msgText)
+}
+
+// SnoozeCommand mocks base method.
+func (m *MockBotSrv) SnoozeCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnoozeCommand", ctx, chatID, msgText)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnoozeCommand indicates an expected call of SnoozeCommand.
+func (mr *MockBotSrvMockRecorder) SnoozeCommand(ctx, chatID, msgText interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnoozeCommand", reflect.TypeOf((*MockBotSrv)(nil).SnoozeCommand), ctx, chatID, msgText)
+}
+
+// SnoozeReminder mocks base method.
+func (m *MockBotSrv) SnoozeReminder(ctx context.Context, chatID int64, id, option string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnoozeReminder", ctx, chatID, id, option)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnoozeReminder indicates an expected call of SnoozeReminder.
+func (mr *MockBotSrvMockRecorder) SnoozeReminder(ctx, chatID, id, option interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnoozeReminder", reflect.TypeOf((*MockBotSrv)(nil).SnoozeReminder), ctx, chatID, id, option)
+}
+
+// SnoozeReminderAt mocks base method.
+func (m *MockBotSrv) SnoozeReminderAt(ctx context.Context, chatID int64, id, msgText string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnoozeReminderAt", ctx, chatID, id, msgText)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnoozeReminderAt indicates an expected call of SnoozeReminderAt.
+func (mr *MockBotSrvMockRecorder) SnoozeReminderAt(ctx, chatID, id, msgText interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnoozeReminderAt", reflect.TypeOf((*MockBotSrv)(nil).SnoozeReminderAt), ctx, chatID, id, msgText)
+}
+
+// StillDue mocks base method.
+func (m *MockBotSrv) StillDue(ctx context.Context, reminder models.Reminder) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StillDue", ctx, reminder)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StillDue indicates an expected call of StillDue.
+func (mr *MockBotSrvMockRecorder) StillDue(ctx, reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StillDue", reflect.TypeOf((*MockBotSrv)(nil).StillDue), ctx, reminder)
+}
+
+// SuspendChat mocks base method.
+func (m *MockBotSrv) SuspendChat(ctx context.Context, chatID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserPage", ctx, chatID, page)
+	ret := m.ctrl.Call(m, "SuspendChat", ctx, chatID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetUserPage indicates an expected call of SetUserPage.
-func (mr *MockBotSrvMockRecorder) SetUserPage(ctx, chatID, page interface{}) *gomock.Call {
+// SuspendChat indicates an expected call of SuspendChat.
+func (mr *MockBotSrvMockRecorder) SuspendChat(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendChat", reflect.TypeOf((*MockBotSrv)(nil).SuspendChat), ctx, chatID)
+}
+
+// TagsCommand mocks base method.
+func (m *MockBotSrv) TagsCommand(ctx context.Context, chatID int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagsCommand", ctx, chatID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagsCommand indicates an expected call of TagsCommand.
+func (mr *MockBotSrvMockRecorder) TagsCommand(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagsCommand", reflect.TypeOf((*MockBotSrv)(nil).TagsCommand), ctx, chatID)
+}
+
+// MockScheduler is a mock of Scheduler interface.
+type MockScheduler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchedulerMockRecorder
+}
+
+// MockSchedulerMockRecorder is the mock recorder for MockScheduler.
+type MockSchedulerMockRecorder struct {
+	mock *MockScheduler
+}
+
+// NewMockScheduler creates a new mock instance.
+func NewMockScheduler(ctrl *gomock.Controller) *MockScheduler {
+	mock := &MockScheduler{ctrl: ctrl}
+	mock.recorder = &MockSchedulerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduler) EXPECT() *MockSchedulerMockRecorder {
+	return m.recorder
+}
+
+// Schedule mocks base method.
+func (m *MockScheduler) Schedule(at time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Schedule", at)
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockSchedulerMockRecorder) Schedule(at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserPage", reflect.TypeOf((*MockBotSrv)(nil).SetUserPage), ctx, chatID, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockScheduler)(nil).Schedule), at)
 }
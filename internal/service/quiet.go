@@ -0,0 +1,148 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const quietUsage = "Тихие часы: /quiet 23:00-07:30 - задать время, /quiet defer - откладывать напоминания до конца тихих часов, " +
+	"/quiet silent - присылать их без звука, /quiet off - выключить. Срочные напоминания, с флагом !, приходят всегда"
+
+// quietWindow - "23:00-07:30".
+var quietWindow = regexp.MustCompile(`^(\d{1,2}:\d{2})[-–—](\d{1,2}:\d{2})$`)
+
+// QuietCommand настраивает тихие часы чата: /quiet, /quiet 23:00-07:30, /quiet silent, /quiet defer, /quiet off.
+func (s *BotSevice) QuietCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	args := strings.Fields(strings.TrimPrefix(msgText, "/quiet"))
+	if len(args) > 1 {
+		return quietUsage, nil
+	}
+	settings, err := s.Store.GetSettings(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	var quiet models.QuietHours
+	if settings.Quiet != nil {
+		quiet = *settings.Quiet
+	}
+	if len(args) == 0 {
+		if !quiet.Enabled {
+			return "Тихие часы выключены. " + quietUsage, nil
+		}
+		return describeQuiet(quiet), nil
+	}
+
+	var message string
+	switch arg := strings.ToLower(args[0]); arg {
+	case "off", "выкл":
+		quiet.Enabled = false
+		message = "Хорошо, тихие часы выключены"
+	case "silent", "тихо":
+		quiet.Silent = true
+		message = "Хорошо, в тихие часы буду присылать напоминания без звука" + quietWindowHint(quiet)
+	case "defer", "отложить":
+		quiet.Silent = false
+		message = "Хорошо, напоминания из тихих часов буду откладывать до их конца" + quietWindowHint(quiet)
+	default:
+		m := quietWindow.FindStringSubmatch(arg)
+		if m == nil {
+			return quietUsage, nil
+		}
+		start, err := time.Parse("15:04", m[1])
+		if err != nil {
+			return quietUsage, nil
+		}
+		end, err := time.Parse("15:04", m[2])
+		if err != nil {
+			return quietUsage, nil
+		}
+		quiet.Start = start.Hour()*60 + start.Minute()
+		quiet.End = end.Hour()*60 + end.Minute()
+		if quiet.Start == quiet.End {
+			return "Начало и конец тихих часов совпадают. Например: /quiet 23:00-07:30", nil
+		}
+		quiet.Enabled = true
+		message = describeQuiet(quiet)
+		if _, known := s.chatZone(ctx, chatID); !known {
+			message += "\nВремя указано по UTC. Чтобы считать по местному времени, укажи часовой пояс: /setlocation или /settz"
+		}
+	}
+	settings.Quiet = &quiet
+	if err := s.Store.SaveSettings(ctx, settings); err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	return message, nil
+}
+
+// CheckQuietHours решает, как отправить сработавшее напоминание с учетом тихих часов чата.
+// В тихие часы напоминание либо уходит без звука (silent), либо переносится на конец окна (deferred) и
+// сейчас не отправляется. Срочные напоминания приходят как обычно.
+func (s *BotSevice) CheckQuietHours(ctx context.Context, reminder models.Reminder) (silent, deferred bool, err error) {
+	if reminder.Urgent {
+		return false, false, nil
+	}
+	settings, err := s.Store.GetSettings(ctx, reminder.ChatID)
+	if err != nil {
+		return false, false, err
+	}
+	quiet := settings.Quiet
+	if quiet == nil || !quiet.Enabled {
+		return false, false, nil
+	}
+	loc := s.reminderLocation(ctx, reminder)
+	// Напоминание захватывается, когда его время уже наступило, но может уйти и позже - после неудачной
+	// отправки или паузы чата, поэтому тихие часы проверяются на момент отправки
+	at := reminder.Time
+	if now := time.Now().UTC(); at.Before(now) {
+		at = now
+	}
+	if !inQuietHours(*quiet, at.In(loc)) {
+		return false, false, nil
+	}
+	if quiet.Silent {
+		return true, false, nil
+	}
+	from := reminder.Time
+	if reminder.DeferredFrom != nil {
+		from = *reminder.DeferredFrom
+	}
+	if err := s.Store.DeferReminder(ctx, reminder.ID, nextLocalMinute(quiet.End, loc, at), from); err != nil {
+		return false, false, err
+	}
+	return false, true, nil
+}
+
+// inQuietHours сообщает, что местное время local попадает в тихие часы. Конец окна в него не входит.
+func inQuietHours(quiet models.QuietHours, local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	if quiet.Start < quiet.End {
+		return minute >= quiet.Start && minute < quiet.End
+	}
+	return minute >= quiet.Start || minute < quiet.End
+}
+
+// quietWindowHint напоминает задать время, если режим выбран раньше самих тихих часов.
+func quietWindowHint(quiet models.QuietHours) string {
+	if quiet.Enabled {
+		return ""
+	}
+	return ". Осталось задать время, например: /quiet 23:00-07:30"
+}
+
+// describeQuiet печатает настройки тихих часов для ответа на /quiet.
+func describeQuiet(quiet models.QuietHours) string {
+	mode := fmt.Sprintf("откладываю до %s", formatMinute(quiet.End))
+	if quiet.Silent {
+		mode = "присылаю без звука"
+	}
+	return fmt.Sprintf("Тихие часы: с %s до %s. Напоминания в это время %s, срочные (с флагом !) - как обычно",
+		formatMinute(quiet.Start), formatMinute(quiet.End), mode)
+}
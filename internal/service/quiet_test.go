@@ -0,0 +1,262 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInQuietHours(t *testing.T) {
+	night := models.QuietHours{Enabled: true, Start: 23 * 60, End: 7*60 + 30}
+	day := models.QuietHours{Enabled: true, Start: 13 * 60, End: 15 * 60}
+	at := func(hour, min int) time.Time { return time.Date(2025, 1, 15, hour, min, 0, 0, time.UTC) }
+	assert.True(t, inQuietHours(night, at(23, 0)))
+	assert.True(t, inQuietHours(night, at(3, 0)))
+	assert.True(t, inQuietHours(night, at(7, 29)))
+	assert.False(t, inQuietHours(night, at(7, 30)))
+	assert.False(t, inQuietHours(night, at(22, 59)))
+	assert.True(t, inQuietHours(day, at(14, 0)))
+	assert.False(t, inQuietHours(day, at(15, 0)))
+	assert.False(t, inQuietHours(day, at(9, 0)))
+}
+
+func TestService_QuietCommand(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore)
+	night := &models.QuietHours{Enabled: true, Start: 23 * 60, End: 7*60 + 30}
+	testTable := []struct {
+		name         string
+		msgText      string
+		mockBehavior mockBehavior
+		wantSaved    *models.QuietHours
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:    "StatusOff",
+			msgText: "/quiet",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			want: "Тихие часы выключены. " + quietUsage,
+		},
+		{
+			name:    "StatusOn",
+			msgText: "/quiet",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, Quiet: night}, nil)
+			},
+			want: "Тихие часы: с 23:00 до 07:30. Напоминания в это время откладываю до 07:30, срочные (с флагом !) - как обычно",
+		},
+		{
+			name:    "Window",
+			msgText: "/quiet 23:00-07:30",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+			},
+			wantSaved: night,
+			want:      "Тихие часы: с 23:00 до 07:30. Напоминания в это время откладываю до 07:30, срочные (с флагом !) - как обычно",
+		},
+		{
+			name:    "WindowWithoutZone",
+			msgText: "/quiet 13:00–15:00",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(models.ChatTimezone{}, errors.New("not found"))
+			},
+			wantSaved: &models.QuietHours{Enabled: true, Start: 13 * 60, End: 15 * 60},
+			want: "Тихие часы: с 13:00 до 15:00. Напоминания в это время откладываю до 15:00, срочные (с флагом !) - как обычно\n" +
+				"Время указано по UTC. Чтобы считать по местному времени, укажи часовой пояс: /setlocation или /settz",
+		},
+		{
+			name:    "Silent",
+			msgText: "/quiet silent",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, Quiet: night}, nil)
+			},
+			wantSaved: &models.QuietHours{Enabled: true, Start: 23 * 60, End: 7*60 + 30, Silent: true},
+			want:      "Хорошо, в тихие часы буду присылать напоминания без звука",
+		},
+		{
+			name:    "ModeBeforeWindow",
+			msgText: "/quiet defer",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			wantSaved: &models.QuietHours{},
+			want:      "Хорошо, напоминания из тихих часов буду откладывать до их конца. Осталось задать время, например: /quiet 23:00-07:30",
+		},
+		{
+			name:    "Off",
+			msgText: "/quiet off",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, Quiet: night}, nil)
+			},
+			wantSaved: &models.QuietHours{Start: 23 * 60, End: 7*60 + 30},
+			want:      "Хорошо, тихие часы выключены",
+		},
+		{
+			name:    "SameStartAndEnd",
+			msgText: "/quiet 23:00-23:00",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			want: "Начало и конец тихих часов совпадают. Например: /quiet 23:00-07:30",
+		},
+		{
+			name:    "BadWindow",
+			msgText: "/quiet ночью",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+			want: quietUsage,
+		},
+		{
+			name:    "SettingsError",
+			msgText: "/quiet off",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{}, errors.New("find error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo)
+			if tt.wantSaved != nil {
+				repo.EXPECT().SaveSettings(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, settings models.ChatSettings) error {
+					assert.Equal(t, *tt.wantSaved, *settings.Quiet)
+					return nil
+				})
+			}
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.QuietCommand(context.TODO(), 1, tt.msgText)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_CheckQuietHours(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	moscow := models.ChatTimezone{Zone: "Europe/Moscow"}
+	night := models.ChatSettings{ChatID: 1, Quiet: &models.QuietHours{Enabled: true, Start: 23 * 60, End: 7*60 + 30}}
+	silent := models.ChatSettings{ChatID: 1, Quiet: &models.QuietHours{Enabled: true, Start: 23 * 60, End: 7*60 + 30, Silent: true}}
+	// 21:00 UTC - полночь по Москве
+	midnight := models.Reminder{
+		ID:           "507f1f77bcf86cd799439011",
+		ChatID:       1,
+		Action:       "полить цветы",
+		Time:         time.Date(2099, 1, 1, 21, 0, 0, 0, time.UTC),
+		OriginalTime: time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	urgent := midnight
+	urgent.Urgent = true
+	evening := midnight
+	evening.Time = time.Date(2099, 1, 1, 17, 0, 0, 0, time.UTC)
+	originally := time.Date(2099, 1, 1, 20, 30, 0, 0, time.UTC)
+	deferredNag := midnight
+	deferredNag.DeferredFrom = &originally
+	morning := time.Date(2099, 1, 2, 4, 30, 0, 0, time.UTC)
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		mockBehavior mockBehavior
+		wantSilent   bool
+		wantDeferred bool
+		wantErr      bool
+	}{
+		{
+			name:     "Urgent",
+			reminder: urgent,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+			},
+		},
+		{
+			name:     "NoQuietHours",
+			reminder: midnight,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+		},
+		{
+			name:     "OutsideWindow",
+			reminder: evening,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(night, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(moscow, nil)
+			},
+		},
+		{
+			name:     "Silent",
+			reminder: midnight,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(silent, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(moscow, nil)
+			},
+			wantSilent: true,
+		},
+		{
+			name:     "Defer",
+			reminder: midnight,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(night, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(moscow, nil)
+				r.EXPECT().DeferReminder(gomock.Any(), reminder.ID, morning, reminder.Time).Return(nil)
+			},
+			wantDeferred: true,
+		},
+		{
+			name:     "DeferKeepsFirstTime",
+			reminder: deferredNag,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(night, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(moscow, nil)
+				r.EXPECT().DeferReminder(gomock.Any(), reminder.ID, morning, originally).Return(nil)
+			},
+			wantDeferred: true,
+		},
+		{
+			name:     "DeferError",
+			reminder: midnight,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetSettings(gomock.Any(), reminder.ChatID).Return(night, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(moscow, nil)
+				r.EXPECT().DeferReminder(gomock.Any(), reminder.ID, morning, reminder.Time).Return(errors.New("update error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+
+			srv := NewBotService(repo, nil)
+			silent, deferred, err := srv.CheckQuietHours(context.TODO(), tt.reminder)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSilent, silent)
+			assert.Equal(t, tt.wantDeferred, deferred)
+		})
+	}
+}
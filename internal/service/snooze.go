@@ -0,0 +1,120 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"JillBot/pkg/timeparse"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Варианты кнопок "отложить" под отправленным напоминанием.
+const (
+	SnoozeTenMinutes = "10m"
+	SnoozeHour       = "1h"
+	SnoozeTomorrow   = "tomorrow"
+)
+
+var (
+	errUnknownSnooze    = errors.New("не понимаю, на сколько отложить напоминание")
+	errReminderNotFound = errors.New("не нашла это напоминание")
+)
+
+// SnoozeReminder откладывает отправленное напоминание на 10 минут, час или до завтра
+// (на то же время на часах) и возвращает новый текст сообщения с напоминанием.
+func (s *BotSevice) SnoozeReminder(ctx context.Context, chatID int64, id, option string) (string, error) {
+	reminder, loc, err := s.snoozedReminder(ctx, chatID, id)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	var when time.Time
+	switch option {
+	case SnoozeTenMinutes:
+		when = now.Add(10 * time.Minute)
+	case SnoozeHour:
+		when = now.Add(time.Hour)
+	case SnoozeTomorrow:
+		if loc == nil {
+			when = now.Add(24 * time.Hour)
+			break
+		}
+		tomorrow := now.In(loc).AddDate(0, 0, 1)
+		when = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(),
+			reminder.OriginalTime.Hour(), reminder.OriginalTime.Minute(), 0, 0, loc)
+	default:
+		return "", errUnknownSnooze
+	}
+	return s.snooze(ctx, reminder, when, loc)
+}
+
+// SnoozeReminderAt откладывает отправленное напоминание на время, которое пользователь написал сам:
+// "через 2 часа", "завтра в 18:00".
+func (s *BotSevice) SnoozeReminderAt(ctx context.Context, chatID int64, id, msgText string) (string, error) {
+	reminder, loc, err := s.snoozedReminder(ctx, chatID, id)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := timeparse.ParseTime(msgText, time.Now().UTC(), loc)
+	if errors.Is(err, timeparse.ErrNeedLocation) {
+		return "", errors.New("я не знаю твоего часового пояса, поэтому могу отложить только на время вроде «через 2 часа». Часовой пояс можно задать через /settz")
+	}
+	if errors.Is(err, timeparse.ErrIncomplete) {
+		return "", errors.New("напиши, когда напомнить, например: через 2 часа или завтра в 18:00")
+	}
+	if err != nil {
+		return "", err
+	}
+	return s.snooze(ctx, reminder, parsed.When, loc)
+}
+
+// snoozedReminder находит напоминание и часовой пояс чата; loc равен nil, если пояс неизвестен.
+func (s *BotSevice) snoozedReminder(ctx context.Context, chatID int64, id string) (models.Reminder, *time.Location, error) {
+	reminder, err := s.Store.GetReminder(ctx, chatID, id)
+	if err != nil {
+		log.Println(err)
+		return reminder, nil, errReminderNotFound
+	}
+	tz, err := s.Store.GetTimezone(ctx, chatID)
+	if err != nil {
+		return reminder, nil, nil
+	}
+	loc, err := chatLocation(tz)
+	if err != nil {
+		return reminder, nil, nil
+	}
+	return reminder, loc, nil
+}
+
+// snooze переносит разовое напоминание на when. Повторяющееся уже переехало на следующее срабатывание,
+// а у напоминания с предупреждениями заранее еще впереди само событие, поэтому для них создается
+// разовая копия, а исходное напоминание остается как было.
+func (s *BotSevice) snooze(ctx context.Context, reminder models.Reminder, when time.Time, loc *time.Location) (string, error) {
+	// Время напоминаний, как и в /remindme, задается и показывается с точностью до минуты
+	when = when.Truncate(time.Minute)
+	local, suffix := when.UTC(), " UTC"
+	if loc != nil {
+		local, suffix = when.In(loc), ""
+	}
+	var err error
+	if reminder.Recurrence != nil || len(reminder.Alerts) > 0 {
+		err = s.Store.AddReminder(ctx, models.Reminder{
+			ChatID:       reminder.ChatID,
+			Action:       reminder.Action,
+			Time:         when.UTC(),
+			OriginalTime: wallClock(local),
+			Urgent:       reminder.Urgent,
+			Tags:         reminder.Tags,
+		})
+	} else {
+		err = s.Store.ReactivateReminder(ctx, reminder.ID, when.UTC(), wallClock(local), nil)
+	}
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	s.schedule(when)
+	return fmt.Sprintf("%s\n\n⏰ Отложено до %s%s", reminder.Action, local.Format("2006-01-02 15:04"), suffix), nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_SnoozeReminder(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore, reminder models.Reminder)
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	oneTime := models.Reminder{
+		ID:           "507f1f77bcf86cd799439011",
+		ChatID:       1,
+		Action:       "позвонить",
+		Time:         time.Date(2024, 10, 10, 6, 30, 0, 0, time.UTC),
+		OriginalTime: time.Date(2024, 10, 10, 9, 30, 0, 0, time.UTC),
+	}
+	recurring := oneTime
+	recurring.Recurrence = &models.Recurrence{Frequency: "daily"}
+	testTable := []struct {
+		name         string
+		reminder     models.Reminder
+		option       string
+		mockBehavior mockBehavior
+		wantAfter    time.Duration
+		wantClock    string
+		wantErr      bool
+	}{
+		{
+			name:     "TenMinutes",
+			reminder: oneTime,
+			option:   SnoozeTenMinutes,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+				r.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID, gomock.Any(), gomock.Any(), gomock.Nil()).
+					DoAndReturn(func(_ context.Context, _ string, utcTime, originalTime time.Time, _ []models.Alert) error {
+						assert.WithinDuration(t, time.Now().Add(10*time.Minute), utcTime, time.Minute)
+						assert.Equal(t, 3*time.Hour, originalTime.Sub(utcTime))
+						return nil
+					})
+			},
+		},
+		{
+			name:     "HourWithoutTimezone",
+			reminder: oneTime,
+			option:   SnoozeHour,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{}, errors.New("not found"))
+				r.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID, gomock.Any(), gomock.Any(), gomock.Nil()).
+					DoAndReturn(func(_ context.Context, _ string, utcTime, originalTime time.Time, _ []models.Alert) error {
+						assert.WithinDuration(t, time.Now().Add(time.Hour), utcTime, time.Minute)
+						assert.Equal(t, utcTime, originalTime)
+						return nil
+					})
+			},
+		},
+		{
+			name:     "TomorrowKeepsClock",
+			reminder: oneTime,
+			option:   SnoozeTomorrow,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+				r.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID, gomock.Any(), gomock.Any(), gomock.Nil()).
+					DoAndReturn(func(_ context.Context, _ string, utcTime, originalTime time.Time, _ []models.Alert) error {
+						tomorrow := time.Now().In(moscow).AddDate(0, 0, 1)
+						assert.Equal(t, time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 30, 0, 0, time.UTC), originalTime)
+						return nil
+					})
+			},
+			wantClock: "09:30",
+		},
+		{
+			name:     "RecurringAddsCopy",
+			reminder: recurring,
+			option:   SnoozeHour,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+				r.EXPECT().AddReminder(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, copy models.Reminder) error {
+						assert.Empty(t, copy.ID)
+						assert.Nil(t, copy.Recurrence)
+						assert.Equal(t, reminder.Action, copy.Action)
+						return nil
+					})
+			},
+		},
+		{
+			name:     "NotFound",
+			reminder: oneTime,
+			option:   SnoozeHour,
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(models.Reminder{}, errors.New("not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name:     "UnknownOption",
+			reminder: oneTime,
+			option:   "1y",
+			mockBehavior: func(r *mock_storage.MockStore, reminder models.Reminder) {
+				r.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "Europe/Moscow"}, nil)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo, tt.reminder)
+
+			srv := NewBotService(repo, nil)
+			text, err := srv.SnoozeReminder(context.TODO(), tt.reminder.ChatID, tt.reminder.ID, tt.option)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(text, "позвонить\n\n⏰ Отложено до "), text)
+			assert.Contains(t, text, tt.wantClock)
+		})
+	}
+}
+
+func TestService_SnoozeReminderAt(t *testing.T) {
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1, Action: "позвонить"}
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mock_storage.NewMockStore(ctrl)
+		repo.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+		repo.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{Zone: "UTC"}, nil)
+		repo.EXPECT().ReactivateReminder(gomock.Any(), reminder.ID, time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC), gomock.Nil()).Return(nil)
+
+		srv := NewBotService(repo, nil)
+		text, err := srv.SnoozeReminderAt(context.TODO(), reminder.ChatID, reminder.ID, "2099-01-01 10:00")
+		assert.NoError(t, err)
+		assert.Equal(t, "позвонить\n\n⏰ Отложено до 2099-01-01 10:00", text)
+	})
+	t.Run("ClockWithoutTimezone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mock_storage.NewMockStore(ctrl)
+		repo.EXPECT().GetReminder(gomock.Any(), reminder.ChatID, reminder.ID).Return(reminder, nil)
+		repo.EXPECT().GetTimezone(gomock.Any(), reminder.ChatID).Return(models.ChatTimezone{}, errors.New("not found"))
+
+		srv := NewBotService(repo, nil)
+		_, err := srv.SnoozeReminderAt(context.TODO(), reminder.ChatID, reminder.ID, "завтра в 18:00")
+		assert.ErrorContains(t, err, "/settz")
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// SuspendChat ставит на паузу напоминания чата, в который бот больше не может писать: пользователь
+// заблокировал бота или бота удалили из группы. Пока чат на паузе, его напоминания и сводки не отправляются.
+func (s *BotSevice) SuspendChat(ctx context.Context, chatID int64) error {
+	suspended, err := s.Store.SuspendChat(ctx, chatID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	log.Printf("Чат %d недоступен, напоминаний на паузе: %d", chatID, suspended)
+	return nil
+}
+
+// ResumeOffer предлагает вернувшемуся чату возобновить напоминания. false - чат не был на паузе
+// или возобновлять нечего; во втором случае пауза снимается сразу.
+func (s *BotSevice) ResumeOffer(ctx context.Context, chatID int64) (string, bool, error) {
+	settings, err := s.Store.GetSettings(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", false, errors.New("Похоже что-то сломалось...")
+	}
+	if settings.SuspendedAt == nil {
+		return "", false, nil
+	}
+	total, missed, err := s.Store.CountSuspendedReminders(ctx, chatID, time.Now().UTC())
+	if err != nil {
+		log.Println(err)
+		return "", false, errors.New("Похоже что-то сломалось...")
+	}
+	if total == 0 {
+		if _, err := s.Store.ResumeChat(ctx, chatID); err != nil {
+			log.Println(err)
+			return "", false, errors.New("Похоже что-то сломалось...")
+		}
+		return "", false, nil
+	}
+	text := fmt.Sprintf("С возвращением! Пока я не могла сюда писать, напоминания стояли на паузе: %d", total)
+	if missed > 0 {
+		text += fmt.Sprintf(", из них пропущено: %d", missed)
+	}
+	return text + ". Возобновить их?", true, nil
+}
+
+// ResumeChat снимает чат с паузы. Пропущенные за это время напоминания приходят сразу,
+// повторяющиеся - один раз, после чего идут по своему расписанию.
+func (s *BotSevice) ResumeChat(ctx context.Context, chatID int64) (string, error) {
+	now := time.Now().UTC()
+	_, missed, err := s.Store.CountSuspendedReminders(ctx, chatID, now)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	resumed, err := s.Store.ResumeChat(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if resumed == 0 {
+		return "Напоминаний на паузе нет", nil
+	}
+	s.schedule(now)
+	text := fmt.Sprintf("Готово, напоминания снова работают: %d", resumed)
+	if missed > 0 {
+		text += fmt.Sprintf(". Пропущенные (%d) пришлю прямо сейчас", missed)
+	}
+	return text, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_service "JillBot/internal/service/mocks"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_SuspendChat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := mock_storage.NewMockStore(ctrl)
+	srv := NewBotService(repo, nil)
+
+	repo.EXPECT().SuspendChat(gomock.Any(), int64(1), gomock.Any()).Return(int64(3), nil)
+	assert.NoError(t, srv.SuspendChat(context.TODO(), 1))
+
+	repo.EXPECT().SuspendChat(gomock.Any(), int64(1), gomock.Any()).Return(int64(0), errors.New("db down"))
+	assert.Error(t, srv.SuspendChat(context.TODO(), 1))
+}
+
+func TestService_ResumeOffer(t *testing.T) {
+	suspendedAt := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	type mockBehavior func(r *mock_storage.MockStore)
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		wantOffer    bool
+		wantResp     string
+		wantErr      bool
+	}{
+		{
+			name: "NotSuspended",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1}, nil)
+			},
+		},
+		{
+			name: "Missed",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, SuspendedAt: &suspendedAt}, nil)
+				r.EXPECT().CountSuspendedReminders(gomock.Any(), int64(1), gomock.Any()).Return(4, 2, nil)
+			},
+			wantOffer: true,
+			wantResp:  "С возвращением! Пока я не могла сюда писать, напоминания стояли на паузе: 4, из них пропущено: 2. Возобновить их?",
+		},
+		{
+			name: "NothingMissed",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, SuspendedAt: &suspendedAt}, nil)
+				r.EXPECT().CountSuspendedReminders(gomock.Any(), int64(1), gomock.Any()).Return(1, 0, nil)
+			},
+			wantOffer: true,
+			wantResp:  "С возвращением! Пока я не могла сюда писать, напоминания стояли на паузе: 1. Возобновить их?",
+		},
+		{
+			name: "NothingToResume",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{ChatID: 1, SuspendedAt: &suspendedAt}, nil)
+				r.EXPECT().CountSuspendedReminders(gomock.Any(), int64(1), gomock.Any()).Return(0, 0, nil)
+				r.EXPECT().ResumeChat(gomock.Any(), int64(1)).Return(int64(0), nil)
+			},
+		},
+		{
+			name: "Error",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.ChatSettings{}, errors.New("db down"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			testCase.mockBehavior(repo)
+			srv := NewBotService(repo, nil)
+
+			text, offer, err := srv.ResumeOffer(context.TODO(), 1)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantOffer, offer)
+			assert.Equal(t, testCase.wantResp, text)
+		})
+	}
+}
+
+func TestService_ResumeChat(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mock_storage.NewMockStore(ctrl)
+		sch := mock_service.NewMockScheduler(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.Scheduler = sch
+		repo.EXPECT().CountSuspendedReminders(gomock.Any(), int64(1), gomock.Any()).Return(3, 2, nil)
+		repo.EXPECT().ResumeChat(gomock.Any(), int64(1)).Return(int64(3), nil)
+		// Пропущенные напоминания уже наступили - планировщик должен проснуться сразу
+		sch.EXPECT().Schedule(gomock.Any())
+
+		text, err := srv.ResumeChat(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Готово, напоминания снова работают: 3. Пропущенные (2) пришлю прямо сейчас", text)
+	})
+	t.Run("AlreadyResumed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		repo := mock_storage.NewMockStore(ctrl)
+		srv := NewBotService(repo, nil)
+		srv.Scheduler = mock_service.NewMockScheduler(ctrl)
+		repo.EXPECT().CountSuspendedReminders(gomock.Any(), int64(1), gomock.Any()).Return(0, 0, nil)
+		repo.EXPECT().ResumeChat(gomock.Any(), int64(1)).Return(int64(0), nil)
+
+		text, err := srv.ResumeChat(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Напоминаний на паузе нет", text)
+	})
+}
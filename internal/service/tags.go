@@ -0,0 +1,112 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const snoozeUsage = "Пожалуйста укажи номер напоминания или тег и на сколько отложить!\n" +
+	"Например: /snooze 3 1h или /snooze #работа 30m"
+
+// TagsCommand перечисляет теги активных напоминаний чата с числом напоминаний.
+func (s *BotSevice) TagsCommand(ctx context.Context, chatID int64) (string, error) {
+	tags, err := s.Store.GetTagCounts(ctx, chatID)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if len(tags) == 0 {
+		return "Тегов пока нет. Добавь #тег в текст напоминания, например: /remindme 18:00 позвонить в банк #финансы", nil
+	}
+	message := "Теги:\n"
+	for _, tag := range tags {
+		message += fmt.Sprintf("#%s - %d\n", tag.Tag, tag.Count)
+	}
+	return message + "\nПоказать напоминания с тегом: /list #тег", nil
+}
+
+// deleteByTag снимает с активных все напоминания чата с тегом.
+func (s *BotSevice) deleteByTag(ctx context.Context, chatID int64, tag string) (string, error) {
+	if hashtag.FindString(tag) != tag {
+		return "Пожалуйста укажи тег целиком, например: /del #финансы", nil
+	}
+	tag = normalizeTag(tag)
+	changes, err := s.Store.MarkRemindersAsInactiveByTag(ctx, chatID, tag)
+	if err != nil {
+		log.Println(err)
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if changes == 0 {
+		return fmt.Sprintf("Напоминаний с тегом #%s не нашлось", tag), nil
+	}
+	return fmt.Sprintf("Удалено напоминаний с тегом #%s: %d", tag, changes), nil
+}
+
+// SnoozeCommand откладывает ближайшее срабатывание напоминания или всех напоминаний с тегом:
+// "/snooze 3 1h", "/snooze #работа 30m".
+func (s *BotSevice) SnoozeCommand(ctx context.Context, chatID int64, msgText string) (string, error) {
+	parts := strings.Fields(strings.TrimPrefix(msgText, "/snooze"))
+	if len(parts) != 2 {
+		return snoozeUsage, nil
+	}
+	d, ok := parseShortDuration(parts[1])
+	if !ok {
+		return snoozeUsage, nil
+	}
+	var reminders []models.Reminder
+	var target string
+	if strings.HasPrefix(parts[0], "#") {
+		if hashtag.FindString(parts[0]) != parts[0] {
+			return snoozeUsage, nil
+		}
+		tag := normalizeTag(parts[0])
+		var err error
+		// limit 0 - все напоминания с тегом сразу
+		reminders, err = s.Store.GetRemindersPage(ctx, chatID, models.ReminderFilter{Tag: tag}, 0, 0)
+		if err != nil {
+			log.Println(err)
+			return "", errors.New("Похоже что-то сломалось...")
+		}
+		if len(reminders) == 0 {
+			return fmt.Sprintf("Напоминаний с тегом #%s не нашлось", tag), nil
+		}
+		target = "#" + tag
+	} else {
+		num, ok := parseNum(parts[0])
+		if !ok {
+			return snoozeUsage, nil
+		}
+		reminder, err := s.listedReminder(ctx, chatID, num)
+		if err != nil {
+			return "", err
+		}
+		reminders = []models.Reminder{reminder}
+	}
+
+	postponed := 0
+	var last string
+	for _, reminder := range reminders {
+		when, err := s.postpone(ctx, reminder, d)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		postponed++
+		last = fmt.Sprintf("Напоминание №%d отложено до %s", reminder.Num, when.Format("2006-01-02 15:04"))
+	}
+	if postponed == 0 {
+		return "", errors.New("Похоже что-то сломалось...")
+	}
+	if target == "" {
+		return last, nil
+	}
+	message := fmt.Sprintf("Отложила на %s напоминаний с тегом %s: %d", formatDuration(d), target, postponed)
+	if postponed < len(reminders) {
+		message += fmt.Sprintf(" (не получилось: %d)", len(reminders)-postponed)
+	}
+	return message, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	mock_storage "JillBot/internal/storage/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTags(t *testing.T) {
+	assert.Nil(t, parseTags("позвонить в банк"))
+	assert.Equal(t, []string{"финансы", "work_2"}, parseTags("#Финансы позвонить в банк #work_2 #финансы"))
+	assert.Equal(t, []string{"дом"}, parseTags("купить лампу (#дом), 2#"))
+}
+
+func TestService_TagsCommand(t *testing.T) {
+	testTable := []struct {
+		name    string
+		tags    []models.TagCount
+		err     error
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "OK",
+			tags: []models.TagCount{{Tag: "работа", Count: 3}, {Tag: "дом", Count: 1}},
+			want: "Теги:\n#работа - 3\n#дом - 1\n\nПоказать напоминания с тегом: /list #тег",
+		},
+		{
+			name: "Empty",
+			want: "Тегов пока нет. Добавь #тег в текст напоминания, например: /remindme 18:00 позвонить в банк #финансы",
+		},
+		{
+			name:    "Error",
+			err:     errors.New("aggregate error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			repo.EXPECT().GetTagCounts(gomock.Any(), int64(1)).Return(tt.tags, tt.err)
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.TagsCommand(context.TODO(), 1)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_SnoozeCommand(t *testing.T) {
+	type mockBehavior func(r *mock_storage.MockStore)
+	reminder := func(num int, hour int) models.Reminder {
+		return models.Reminder{
+			ID:           "507f1f77bcf86cd79943901" + string(rune('0'+num)),
+			ChatID:       1,
+			Num:          num,
+			Action:       "отчет #работа",
+			Tags:         []string{"работа"},
+			Time:         time.Date(2099, 1, 1, hour, 0, 0, 0, time.UTC),
+			OriginalTime: time.Date(2099, 1, 1, hour, 0, 0, 0, time.UTC),
+			IsActive:     true,
+		}
+	}
+	moved := func(r models.Reminder, d time.Duration) models.Reminder {
+		r.Time = r.Time.Add(d)
+		r.OriginalTime = r.OriginalTime.Add(d)
+		return r
+	}
+	utc := models.ChatTimezone{Zone: "UTC"}
+	testTable := []struct {
+		name         string
+		msgText      string
+		mockBehavior mockBehavior
+		want         string
+		wantErr      error
+	}{
+		{
+			name:    "ByNum",
+			msgText: "/snooze 3 30m",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), int64(1), 3).Return(reminder(3, 9), nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(3, 9), 30*time.Minute)).Return(int64(1), nil)
+			},
+			want: "Напоминание №3 отложено до 2099-01-01 09:30",
+		},
+		{
+			name:    "ByTag",
+			msgText: "/snooze #Работа 1h",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "работа"}, 0, 0).
+					Return([]models.Reminder{reminder(1, 9), reminder(2, 11)}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil).Times(2)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(1, 9), time.Hour)).Return(int64(1), nil)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(2, 11), time.Hour)).Return(int64(1), nil)
+			},
+			want: "Отложила на 1 ч напоминаний с тегом #работа: 2",
+		},
+		{
+			name:    "ByTagDailyOneDay",
+			msgText: "/snooze #работа 1d",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				daily := reminder(1, 9)
+				daily.Recurrence = &models.Recurrence{Frequency: "daily"}
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "работа"}, 0, 0).
+					Return([]models.Reminder{daily}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil).Times(2)
+				// Отложенный раз совпал со следующим повтором - копия не нужна
+				next := time.Date(2099, 1, 2, 9, 0, 0, 0, time.UTC)
+				r.EXPECT().ReactivateReminder(gomock.Any(), daily.ID, next, next, gomock.Nil()).Return(nil)
+			},
+			want: "Отложила на 1 дн напоминаний с тегом #работа: 1",
+		},
+		{
+			name:    "ByTagDailyTwoDays",
+			msgText: "/snooze #работа 2d",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				daily := reminder(1, 9)
+				daily.Recurrence = &models.Recurrence{Frequency: "daily"}
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "работа"}, 0, 0).
+					Return([]models.Reminder{daily}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil).Times(2)
+				moved := time.Date(2099, 1, 3, 9, 0, 0, 0, time.UTC)
+				r.EXPECT().AddReminder(gomock.Any(), models.Reminder{
+					ChatID: 1, Action: daily.Action, Tags: daily.Tags, Time: moved, OriginalTime: moved,
+				}).Return(nil)
+				// Повторы внутри переноса пропускаются, серия продолжается после отложенного раза
+				next := time.Date(2099, 1, 4, 9, 0, 0, 0, time.UTC)
+				r.EXPECT().ReactivateReminder(gomock.Any(), daily.ID, next, next, gomock.Nil()).Return(nil)
+			},
+			want: "Отложила на 2 дн напоминаний с тегом #работа: 1",
+		},
+		{
+			name:    "ByTagPartly",
+			msgText: "/snooze #работа 1h",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "работа"}, 0, 0).
+					Return([]models.Reminder{reminder(1, 9), reminder(2, 11)}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil).Times(2)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(1, 9), time.Hour)).Return(int64(1), nil)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(2, 11), time.Hour)).Return(int64(0), errors.New("update error"))
+			},
+			want: "Отложила на 1 ч напоминаний с тегом #работа: 1 (не получилось: 1)",
+		},
+		{
+			name:    "NumericTag",
+			msgText: "/snooze #2024 1h",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "2024"}, 0, 0).
+					Return([]models.Reminder{reminder(1, 9)}, nil)
+				r.EXPECT().GetTimezone(gomock.Any(), int64(1)).Return(utc, nil)
+				r.EXPECT().UpdateReminder(gomock.Any(), moved(reminder(1, 9), time.Hour)).Return(int64(1), nil)
+			},
+			want: "Отложила на 1 ч напоминаний с тегом #2024: 1",
+		},
+		{
+			name:    "TagNotFound",
+			msgText: "/snooze #отпуск 1h",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetRemindersPage(gomock.Any(), int64(1), models.ReminderFilter{Tag: "отпуск"}, 0, 0).Return(nil, nil)
+			},
+			want: "Напоминаний с тегом #отпуск не нашлось",
+		},
+		{
+			name:    "NumNotFound",
+			msgText: "/snooze 7 1h",
+			mockBehavior: func(r *mock_storage.MockStore) {
+				r.EXPECT().GetReminderByNum(gomock.Any(), int64(1), 7).Return(models.Reminder{}, errors.New("not found"))
+			},
+			wantErr: errReminderNotFound,
+		},
+		{
+			name:         "BadDuration",
+			msgText:      "/snooze 3 потом",
+			mockBehavior: func(r *mock_storage.MockStore) {},
+			want:         snoozeUsage,
+		},
+		{
+			name:         "NoArgs",
+			msgText:      "/snooze",
+			mockBehavior: func(r *mock_storage.MockStore) {},
+			want:         snoozeUsage,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := mock_storage.NewMockStore(ctrl)
+			tt.mockBehavior(repo)
+
+			srv := NewBotService(repo, nil)
+			got, err := srv.SnoozeCommand(context.TODO(), 1, tt.msgText)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"JillBot/internal/models"
+	"JillBot/pkg/timeparse"
+	"JillBot/pkg/tzresolver"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownTimezone возвращается, если напоминание привязано к часам, а часовой пояс чата неизвестен.
+var ErrUnknownTimezone = timeparse.ErrNeedLocation
+
+// ErrUnknownZone возвращается, если /settz не смог понять, какой часовой пояс имелся в виду.
+var ErrUnknownZone = errors.New("unknown zone")
+
+var offsetZoneRe = regexp.MustCompile(`^(?i:utc|gmt)?\s*([+-])\s*(\d{1,2})(?::?(\d{2}))?$`)
+
+func chatLocation(tz models.ChatTimezone) (*time.Location, error) {
+	loc, err := loadZone(tz.Zone)
+	if err != nil || tz.Zone == "" {
+		log.Printf("Неизвестная зона %q у чата %d: %v", tz.Zone, tz.ChatID, err)
+		return nil, errors.New("не получается разобрать твой часовой пояс, задай его заново через /setlocation или /settz")
+	}
+	return loc, nil
+}
+
+// loadZone загружает зону IANA или постоянный сдвиг вида UTC+05:30, для которого в tzdb нет зоны.
+func loadZone(name string) (*time.Location, error) {
+	if strings.HasPrefix(name, "UTC+") || strings.HasPrefix(name, "UTC-") {
+		if offset, ok := parseOffset(name); ok {
+			return time.FixedZone(name, offset), nil
+		}
+	}
+	return time.LoadLocation(name)
+}
+
+// resolveZone понимает часовой пояс, указанный вручную: имя зоны IANA ("Europe/Moscow"),
+// сдвиг от UTC ("UTC+3", "+05:30") или город ("Москва", "London").
+func resolveZone(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if strings.EqualFold(query, "utc") || strings.EqualFold(query, "gmt") {
+		return "UTC", nil
+	}
+	if offset, ok := parseOffset(query); ok {
+		if offset%3600 == 0 {
+			return fixedZoneName(offset / 3600), nil
+		}
+		sign := '+'
+		if offset < 0 {
+			sign, offset = '-', -offset
+		}
+		return fmt.Sprintf("UTC%c%02d:%02d", sign, offset/3600, offset%3600/60), nil
+	}
+	if zone, ok := tzresolver.Lookup(query); ok {
+		return zone, nil
+	}
+	if strings.Contains(query, "/") {
+		if _, err := time.LoadLocation(query); err == nil {
+			return query, nil
+		}
+	}
+	return "", ErrUnknownZone
+}
+
+// parseOffset разбирает сдвиг от UTC и возвращает его в секундах.
+func parseOffset(s string) (int, bool) {
+	m := offsetZoneRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+	hours, _ := strconv.Atoi(m[2])
+	minutes := 0
+	if m[3] != "" {
+		minutes, _ = strconv.Atoi(m[3])
+	}
+	if hours > 14 || minutes >= 60 || hours == 14 && minutes > 0 {
+		return 0, false
+	}
+	offset := hours*3600 + minutes*60
+	if m[1] == "-" {
+		offset = -offset
+	}
+	return offset, true
+}
+
+// chatZone возвращает часовой пояс чата; false, если он не задан или не читается.
+func (s *BotSevice) chatZone(ctx context.Context, chatID int64) (*time.Location, bool) {
+	tz, err := s.Store.GetTimezone(ctx, chatID)
+	if err != nil {
+		return time.UTC, false
+	}
+	loc, err := chatLocation(tz)
+	if err != nil {
+		return time.UTC, false
+	}
+	return loc, true
+}
+
+// reminderLocation возвращает часовой пояс чата напоминания. Если он удален или не читается,
+// используется сдвиг, с которым напоминание было создано.
+func (s *BotSevice) reminderLocation(ctx context.Context, reminder models.Reminder) *time.Location {
+	if tz, err := s.Store.GetTimezone(ctx, reminder.ChatID); err == nil {
+		if loc, err := chatLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return time.FixedZone("", int(reminder.OriginalTime.Sub(eventTime(reminder)).Seconds()))
+}
+
+// fixedZoneName возвращает зону с постоянным сдвигом. Знак в именах Etc/GMT обратный: UTC+3 это Etc/GMT-3.
+func fixedZoneName(diffHour int) string {
+	if diffHour == 0 {
+		return "UTC"
+	}
+	return fmt.Sprintf("Etc/GMT%+d", -diffHour)
+}
+
+// wallClock переводит время в формат поля time напоминания: местное время пользователя, записанное как UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// fromWallClock - обратное к wallClock: то же время на часах, но в часовом поясе loc.
+func fromWallClock(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+}
+
+func recurrenceToModel(rec *timeparse.Recurrence) *models.Recurrence {
+	if rec == nil {
+		return nil
+	}
+	return &models.Recurrence{
+		Frequency: string(rec.Frequency),
+		Weekdays:  rec.Weekdays,
+		Day:       rec.Day,
+	}
+}
+
+func recurrenceFromModel(rec *models.Recurrence) *timeparse.Recurrence {
+	if rec == nil {
+		return nil
+	}
+	return &timeparse.Recurrence{
+		Frequency: timeparse.Frequency(rec.Frequency),
+		Weekdays:  rec.Weekdays,
+		Day:       rec.Day,
+	}
+}
+
+// formatDuration печатает длительность по-человечески: "1 ч 30 мин", "2 дн 3 ч".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	var parts []string
+	if days > 0 {
+		parts = append(parts, strconv.Itoa(days)+" дн")
+	}
+	if hours > 0 {
+		parts = append(parts, strconv.Itoa(hours)+" ч")
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, strconv.Itoa(minutes)+" мин")
+	}
+	return strings.Join(parts, " ")
+}
@@ -8,6 +8,7 @@ import (
 	models "JillBot/internal/models"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +36,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcknowledgeReminder mocks base method.
+func (m *MockStore) AcknowledgeReminder(ctx context.Context, chatID int64, id string, at time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgeReminder", ctx, chatID, id, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcknowledgeReminder indicates an expected call of AcknowledgeReminder.
+func (mr *MockStoreMockRecorder) AcknowledgeReminder(ctx, chatID, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgeReminder", reflect.TypeOf((*MockStore)(nil).AcknowledgeReminder), ctx, chatID, id, at)
+}
+
 // AddReminder mocks base method.
 func (m *MockStore) AddReminder(ctx context.Context, reminder models.Reminder) error {
 	m.ctrl.T.Helper()
@@ -50,17 +66,93 @@ func (mr *MockStoreMockRecorder) AddReminder(ctx, reminder interface{}) *gomock.
 }
 
 // AddTimezone mocks base method.
-func (m *MockStore) AddTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error {
+func (m *MockStore) AddTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddTimezone", ctx, chatID, lat, long, diffhour)
+	ret := m.ctrl.Call(m, "AddTimezone", ctx, chatID, lat, long, zone)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddTimezone indicates an expected call of AddTimezone.
-func (mr *MockStoreMockRecorder) AddTimezone(ctx, chatID, lat, long, diffhour interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) AddTimezone(ctx, chatID, lat, long, zone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTimezone", reflect.TypeOf((*MockStore)(nil).AddTimezone), ctx, chatID, lat, long, zone)
+}
+
+// AdvanceDigest mocks base method.
+func (m *MockStore) AdvanceDigest(ctx context.Context, chatID int64, from, next time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceDigest", ctx, chatID, from, next)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceDigest indicates an expected call of AdvanceDigest.
+func (mr *MockStoreMockRecorder) AdvanceDigest(ctx, chatID, from, next interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTimezone", reflect.TypeOf((*MockStore)(nil).AddTimezone), ctx, chatID, lat, long, diffhour)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceDigest", reflect.TypeOf((*MockStore)(nil).AdvanceDigest), ctx, chatID, from, next)
+}
+
+// ClaimDueReminder mocks base method.
+func (m *MockStore) ClaimDueReminder(ctx context.Context, owner string, now, leaseUntil time.Time) (models.Reminder, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueReminder", ctx, owner, now, leaseUntil)
+	ret0, _ := ret[0].(models.Reminder)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ClaimDueReminder indicates an expected call of ClaimDueReminder.
+func (mr *MockStoreMockRecorder) ClaimDueReminder(ctx, owner, now, leaseUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueReminder", reflect.TypeOf((*MockStore)(nil).ClaimDueReminder), ctx, owner, now, leaseUntil)
+}
+
+// CountReminders mocks base method.
+func (m *MockStore) CountReminders(ctx context.Context, chatID int64, filter models.ReminderFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountReminders", ctx, chatID, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountReminders indicates an expected call of CountReminders.
+func (mr *MockStoreMockRecorder) CountReminders(ctx, chatID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountReminders", reflect.TypeOf((*MockStore)(nil).CountReminders), ctx, chatID, filter)
+}
+
+// CountSuspendedReminders mocks base method.
+func (m *MockStore) CountSuspendedReminders(ctx context.Context, chatID int64, now time.Time) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSuspendedReminders", ctx, chatID, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountSuspendedReminders indicates an expected call of CountSuspendedReminders.
+func (mr *MockStoreMockRecorder) CountSuspendedReminders(ctx, chatID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSuspendedReminders", reflect.TypeOf((*MockStore)(nil).CountSuspendedReminders), ctx, chatID, now)
+}
+
+// DeferReminder mocks base method.
+func (m *MockStore) DeferReminder(ctx context.Context, id string, until, from time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferReminder", ctx, id, until, from)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferReminder indicates an expected call of DeferReminder.
+func (mr *MockStoreMockRecorder) DeferReminder(ctx, id, until, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferReminder", reflect.TypeOf((*MockStore)(nil).DeferReminder), ctx, id, until, from)
 }
 
 // DeleteTimezone mocks base method.
@@ -77,6 +169,96 @@ func (mr *MockStoreMockRecorder) DeleteTimezone(ctx, chatID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTimezone", reflect.TypeOf((*MockStore)(nil).DeleteTimezone), ctx, chatID)
 }
 
+// ExtendLease mocks base method.
+func (m *MockStore) ExtendLease(ctx context.Context, id, owner string, until time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendLease", ctx, id, owner, until)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExtendLease indicates an expected call of ExtendLease.
+func (mr *MockStoreMockRecorder) ExtendLease(ctx, id, owner, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendLease", reflect.TypeOf((*MockStore)(nil).ExtendLease), ctx, id, owner, until)
+}
+
+// GetDueDigests mocks base method.
+func (m *MockStore) GetDueDigests(ctx context.Context, now time.Time) ([]models.ChatSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueDigests", ctx, now)
+	ret0, _ := ret[0].([]models.ChatSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueDigests indicates an expected call of GetDueDigests.
+func (mr *MockStoreMockRecorder) GetDueDigests(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueDigests", reflect.TypeOf((*MockStore)(nil).GetDueDigests), ctx, now)
+}
+
+// GetFailedReminders mocks base method.
+func (m *MockStore) GetFailedReminders(ctx context.Context, limit int) ([]models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailedReminders", ctx, limit)
+	ret0, _ := ret[0].([]models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailedReminders indicates an expected call of GetFailedReminders.
+func (mr *MockStoreMockRecorder) GetFailedReminders(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedReminders", reflect.TypeOf((*MockStore)(nil).GetFailedReminders), ctx, limit)
+}
+
+// GetLegacyTimezones mocks base method.
+func (m *MockStore) GetLegacyTimezones(ctx context.Context) ([]models.LegacyTimezone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegacyTimezones", ctx)
+	ret0, _ := ret[0].([]models.LegacyTimezone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLegacyTimezones indicates an expected call of GetLegacyTimezones.
+func (mr *MockStoreMockRecorder) GetLegacyTimezones(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegacyTimezones", reflect.TypeOf((*MockStore)(nil).GetLegacyTimezones), ctx)
+}
+
+// GetReminder mocks base method.
+func (m *MockStore) GetReminder(ctx context.Context, chatID int64, id string) (models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminder", ctx, chatID, id)
+	ret0, _ := ret[0].(models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminder indicates an expected call of GetReminder.
+func (mr *MockStoreMockRecorder) GetReminder(ctx, chatID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminder", reflect.TypeOf((*MockStore)(nil).GetReminder), ctx, chatID, id)
+}
+
+// GetReminderByNum mocks base method.
+func (m *MockStore) GetReminderByNum(ctx context.Context, chatID int64, num int) (models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReminderByNum", ctx, chatID, num)
+	ret0, _ := ret[0].(models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReminderByNum indicates an expected call of GetReminderByNum.
+func (mr *MockStoreMockRecorder) GetReminderByNum(ctx, chatID, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminderByNum", reflect.TypeOf((*MockStore)(nil).GetReminderByNum), ctx, chatID, num)
+}
+
 // GetReminders mocks base method.
 func (m *MockStore) GetReminders(ctx context.Context, chatID int64) ([]models.Reminder, error) {
 	m.ctrl.T.Helper()
@@ -92,6 +274,51 @@ func (mr *MockStoreMockRecorder) GetReminders(ctx, chatID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReminders", reflect.TypeOf((*MockStore)(nil).GetReminders), ctx, chatID)
 }
 
+// GetRemindersPage mocks base method.
+func (m *MockStore) GetRemindersPage(ctx context.Context, chatID int64, filter models.ReminderFilter, skip, limit int) ([]models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRemindersPage", ctx, chatID, filter, skip, limit)
+	ret0, _ := ret[0].([]models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRemindersPage indicates an expected call of GetRemindersPage.
+func (mr *MockStoreMockRecorder) GetRemindersPage(ctx, chatID, filter, skip, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemindersPage", reflect.TypeOf((*MockStore)(nil).GetRemindersPage), ctx, chatID, filter, skip, limit)
+}
+
+// GetSettings mocks base method.
+func (m *MockStore) GetSettings(ctx context.Context, chatID int64) (models.ChatSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, chatID)
+	ret0, _ := ret[0].(models.ChatSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockStoreMockRecorder) GetSettings(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockStore)(nil).GetSettings), ctx, chatID)
+}
+
+// GetTagCounts mocks base method.
+func (m *MockStore) GetTagCounts(ctx context.Context, chatID int64) ([]models.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagCounts", ctx, chatID)
+	ret0, _ := ret[0].([]models.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagCounts indicates an expected call of GetTagCounts.
+func (mr *MockStoreMockRecorder) GetTagCounts(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagCounts", reflect.TypeOf((*MockStore)(nil).GetTagCounts), ctx, chatID)
+}
+
 // GetTimezone mocks base method.
 func (m *MockStore) GetTimezone(ctx context.Context, chatID int64) (models.ChatTimezone, error) {
 	m.ctrl.T.Helper()
@@ -107,74 +334,304 @@ func (mr *MockStoreMockRecorder) GetTimezone(ctx, chatID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimezone", reflect.TypeOf((*MockStore)(nil).GetTimezone), ctx, chatID)
 }
 
+// GetUnnumberedReminders mocks base method.
+func (m *MockStore) GetUnnumberedReminders(ctx context.Context) ([]models.Reminder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnnumberedReminders", ctx)
+	ret0, _ := ret[0].([]models.Reminder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnnumberedReminders indicates an expected call of GetUnnumberedReminders.
+func (mr *MockStoreMockRecorder) GetUnnumberedReminders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnnumberedReminders", reflect.TypeOf((*MockStore)(nil).GetUnnumberedReminders), ctx)
+}
+
 // GetUpcomingReminders mocks base method.
-func (m *MockStore) GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error) {
+func (m *MockStore) GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUpcomingReminders", ctx)
+	ret := m.ctrl.Call(m, "GetUpcomingReminders", ctx, until)
 	ret0, _ := ret[0].([]models.Reminder)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUpcomingReminders indicates an expected call of GetUpcomingReminders.
-func (mr *MockStoreMockRecorder) GetUpcomingReminders(ctx interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) GetUpcomingReminders(ctx, until interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingReminders", reflect.TypeOf((*MockStore)(nil).GetUpcomingReminders), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpcomingReminders", reflect.TypeOf((*MockStore)(nil).GetUpcomingReminders), ctx, until)
 }
 
-// GetUserPage mocks base method.
-func (m *MockStore) GetUserPage(ctx context.Context, chatID int64) int {
+// MarkReminderAsDelivered mocks base method.
+func (m *MockStore) MarkReminderAsDelivered(ctx context.Context, id, owner string, deliveredAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserPage", ctx, chatID)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "MarkReminderAsDelivered", ctx, id, owner, deliveredAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkReminderAsDelivered indicates an expected call of MarkReminderAsDelivered.
+func (mr *MockStoreMockRecorder) MarkReminderAsDelivered(ctx, id, owner, deliveredAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsDelivered", reflect.TypeOf((*MockStore)(nil).MarkReminderAsDelivered), ctx, id, owner, deliveredAt)
+}
+
+// MarkReminderAsFailed mocks base method.
+func (m *MockStore) MarkReminderAsFailed(ctx context.Context, id, owner string, attempts int, lastError string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkReminderAsFailed", ctx, id, owner, attempts, lastError, at)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// GetUserPage indicates an expected call of GetUserPage.
-func (mr *MockStoreMockRecorder) GetUserPage(ctx, chatID interface{}) *gomock.Call {
+// MarkReminderAsFailed indicates an expected call of MarkReminderAsFailed.
+func (mr *MockStoreMockRecorder) MarkReminderAsFailed(ctx, id, owner, attempts, lastError, at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPage", reflect.TypeOf((*MockStore)(nil).GetUserPage), ctx, chatID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsFailed", reflect.TypeOf((*MockStore)(nil).MarkReminderAsFailed), ctx, id, owner, attempts, lastError, at)
 }
 
 // MarkReminderAsInactive mocks base method.
-func (m *MockStore) MarkReminderAsInactive(ctx context.Context, chatID int64, id string) (int64, error) {
+func (m *MockStore) MarkReminderAsInactive(ctx context.Context, chatID int64, num int) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkReminderAsInactive", ctx, chatID, id)
+	ret := m.ctrl.Call(m, "MarkReminderAsInactive", ctx, chatID, num)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // MarkReminderAsInactive indicates an expected call of MarkReminderAsInactive.
-func (mr *MockStoreMockRecorder) MarkReminderAsInactive(ctx, chatID, id interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) MarkReminderAsInactive(ctx, chatID, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsInactive", reflect.TypeOf((*MockStore)(nil).MarkReminderAsInactive), ctx, chatID, num)
+}
+
+// MarkRemindersAsInactiveByTag mocks base method.
+func (m *MockStore) MarkRemindersAsInactiveByTag(ctx context.Context, chatID int64, tag string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRemindersAsInactiveByTag", ctx, chatID, tag)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRemindersAsInactiveByTag indicates an expected call of MarkRemindersAsInactiveByTag.
+func (mr *MockStoreMockRecorder) MarkRemindersAsInactiveByTag(ctx, chatID, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRemindersAsInactiveByTag", reflect.TypeOf((*MockStore)(nil).MarkRemindersAsInactiveByTag), ctx, chatID, tag)
+}
+
+// NextReminderNum mocks base method.
+func (m *MockStore) NextReminderNum(ctx context.Context, chatID int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextReminderNum", ctx, chatID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextReminderNum indicates an expected call of NextReminderNum.
+func (mr *MockStoreMockRecorder) NextReminderNum(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextReminderNum", reflect.TypeOf((*MockStore)(nil).NextReminderNum), ctx, chatID)
+}
+
+// ReactivateReminder mocks base method.
+func (m *MockStore) ReactivateReminder(ctx context.Context, id string, utcTime, originalTime time.Time, alerts []models.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateReminder", ctx, id, utcTime, originalTime, alerts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateReminder indicates an expected call of ReactivateReminder.
+func (mr *MockStoreMockRecorder) ReactivateReminder(ctx, id, utcTime, originalTime, alerts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateReminder", reflect.TypeOf((*MockStore)(nil).ReactivateReminder), ctx, id, utcTime, originalTime, alerts)
+}
+
+// RecordDeliveryFailure mocks base method.
+func (m *MockStore) RecordDeliveryFailure(ctx context.Context, id, owner string, attempts int, lastError string, retryAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeliveryFailure", ctx, id, owner, attempts, lastError, retryAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeliveryFailure indicates an expected call of RecordDeliveryFailure.
+func (mr *MockStoreMockRecorder) RecordDeliveryFailure(ctx, id, owner, attempts, lastError, retryAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryFailure", reflect.TypeOf((*MockStore)(nil).RecordDeliveryFailure), ctx, id, owner, attempts, lastError, retryAt)
+}
+
+// ReleaseLease mocks base method.
+func (m *MockStore) ReleaseLease(ctx context.Context, id, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLease", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLease indicates an expected call of ReleaseLease.
+func (mr *MockStoreMockRecorder) ReleaseLease(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLease", reflect.TypeOf((*MockStore)(nil).ReleaseLease), ctx, id, owner)
+}
+
+// RescheduleReminder mocks base method.
+func (m *MockStore) RescheduleReminder(ctx context.Context, id, owner string, utcTime, originalTime time.Time, alerts []models.Alert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleReminder", ctx, id, owner, utcTime, originalTime, alerts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleReminder indicates an expected call of RescheduleReminder.
+func (mr *MockStoreMockRecorder) RescheduleReminder(ctx, id, owner, utcTime, originalTime, alerts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleReminder", reflect.TypeOf((*MockStore)(nil).RescheduleReminder), ctx, id, owner, utcTime, originalTime, alerts)
+}
+
+// RescheduleReminders mocks base method.
+func (m *MockStore) RescheduleReminders(ctx context.Context, reminders []models.Reminder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleReminders", ctx, reminders)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RescheduleReminders indicates an expected call of RescheduleReminders.
+func (mr *MockStoreMockRecorder) RescheduleReminders(ctx, reminders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleReminders", reflect.TypeOf((*MockStore)(nil).RescheduleReminders), ctx, reminders)
+}
+
+// ResumeChat mocks base method.
+func (m *MockStore) ResumeChat(ctx context.Context, chatID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeChat", ctx, chatID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeChat indicates an expected call of ResumeChat.
+func (mr *MockStoreMockRecorder) ResumeChat(ctx, chatID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeChat", reflect.TypeOf((*MockStore)(nil).ResumeChat), ctx, chatID)
+}
+
+// SaveSettings mocks base method.
+func (m *MockStore) SaveSettings(ctx context.Context, settings models.ChatSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSettings", ctx, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings.
+func (mr *MockStoreMockRecorder) SaveSettings(ctx, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockStore)(nil).SaveSettings), ctx, settings)
+}
+
+// ScheduleNag mocks base method.
+func (m *MockStore) ScheduleNag(ctx context.Context, id, owner string, deliveredAt, next time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleNag", ctx, id, owner, deliveredAt, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleNag indicates an expected call of ScheduleNag.
+func (mr *MockStoreMockRecorder) ScheduleNag(ctx, id, owner, deliveredAt, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleNag", reflect.TypeOf((*MockStore)(nil).ScheduleNag), ctx, id, owner, deliveredAt, next)
+}
+
+// SetReminderNum mocks base method.
+func (m *MockStore) SetReminderNum(ctx context.Context, id string, num int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReminderNum", ctx, id, num)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReminderNum indicates an expected call of SetReminderNum.
+func (mr *MockStoreMockRecorder) SetReminderNum(ctx, id, num interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReminderNum", reflect.TypeOf((*MockStore)(nil).SetReminderNum), ctx, id, num)
+}
+
+// SetZone mocks base method.
+func (m *MockStore) SetZone(ctx context.Context, chatID int64, zone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetZone", ctx, chatID, zone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetZone indicates an expected call of SetZone.
+func (mr *MockStoreMockRecorder) SetZone(ctx, chatID, zone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetZone", reflect.TypeOf((*MockStore)(nil).SetZone), ctx, chatID, zone)
+}
+
+// SuspendChat mocks base method.
+func (m *MockStore) SuspendChat(ctx context.Context, chatID int64, at time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendChat", ctx, chatID, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuspendChat indicates an expected call of SuspendChat.
+func (mr *MockStoreMockRecorder) SuspendChat(ctx, chatID, at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReminderAsInactive", reflect.TypeOf((*MockStore)(nil).MarkReminderAsInactive), ctx, chatID, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendChat", reflect.TypeOf((*MockStore)(nil).SuspendChat), ctx, chatID, at)
 }
 
-// SetUserPage mocks base method.
-func (m *MockStore) SetUserPage(ctx context.Context, chatID int64, page int) error {
+// UpdateAlerts mocks base method.
+func (m *MockStore) UpdateAlerts(ctx context.Context, id, owner string, alerts []models.Alert, next time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUserPage", ctx, chatID, page)
+	ret := m.ctrl.Call(m, "UpdateAlerts", ctx, id, owner, alerts, next)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetUserPage indicates an expected call of SetUserPage.
-func (mr *MockStoreMockRecorder) SetUserPage(ctx, chatID, page interface{}) *gomock.Call {
+// UpdateAlerts indicates an expected call of UpdateAlerts.
+func (mr *MockStoreMockRecorder) UpdateAlerts(ctx, id, owner, alerts, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAlerts", reflect.TypeOf((*MockStore)(nil).UpdateAlerts), ctx, id, owner, alerts, next)
+}
+
+// UpdateReminder mocks base method.
+func (m *MockStore) UpdateReminder(ctx context.Context, reminder models.Reminder) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReminder", ctx, reminder)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateReminder indicates an expected call of UpdateReminder.
+func (mr *MockStoreMockRecorder) UpdateReminder(ctx, reminder interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserPage", reflect.TypeOf((*MockStore)(nil).SetUserPage), ctx, chatID, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReminder", reflect.TypeOf((*MockStore)(nil).UpdateReminder), ctx, reminder)
 }
 
 // UpdateTimezone mocks base method.
-func (m *MockStore) UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error {
+func (m *MockStore) UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTimezone", ctx, chatID, lat, long, diffhour)
+	ret := m.ctrl.Call(m, "UpdateTimezone", ctx, chatID, lat, long, zone)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateTimezone indicates an expected call of UpdateTimezone.
-func (mr *MockStoreMockRecorder) UpdateTimezone(ctx, chatID, lat, long, diffhour interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) UpdateTimezone(ctx, chatID, lat, long, zone interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimezone", reflect.TypeOf((*MockStore)(nil).UpdateTimezone), ctx, chatID, lat, long, diffhour)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimezone", reflect.TypeOf((*MockStore)(nil).UpdateTimezone), ctx, chatID, lat, long, zone)
 }
@@ -4,56 +4,129 @@ import (
 	"JillBot/internal/models"
 	"context"
 	"errors"
-	"fmt"
 	"log"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 //go:generate mockgen -source=reminders.go -destination=mocks/mock.go
 type Store interface {
 	AddReminder(ctx context.Context, reminder models.Reminder) error
 	GetReminders(ctx context.Context, chatID int64) ([]models.Reminder, error)
-	GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error)
-	MarkReminderAsInactive(ctx context.Context, chatID int64, id string) (int64, error)
+	CountReminders(ctx context.Context, chatID int64, filter models.ReminderFilter) (int, error)
+	GetRemindersPage(ctx context.Context, chatID int64, filter models.ReminderFilter, skip, limit int) ([]models.Reminder, error)
+	GetReminder(ctx context.Context, chatID int64, id string) (models.Reminder, error)
+	GetReminderByNum(ctx context.Context, chatID int64, num int) (models.Reminder, error)
+	UpdateReminder(ctx context.Context, reminder models.Reminder) (int64, error)
+	GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error)
+	ClaimDueReminder(ctx context.Context, owner string, now, leaseUntil time.Time) (models.Reminder, bool, error)
+	ExtendLease(ctx context.Context, id string, owner string, until time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, id string, owner string) error
+	RecordDeliveryFailure(ctx context.Context, id string, owner string, attempts int, lastError string, retryAt time.Time) error
+	MarkReminderAsFailed(ctx context.Context, id string, owner string, attempts int, lastError string, at time.Time) error
+	GetFailedReminders(ctx context.Context, limit int) ([]models.Reminder, error)
+	MarkReminderAsInactive(ctx context.Context, chatID int64, num int) (int64, error)
+	MarkRemindersAsInactiveByTag(ctx context.Context, chatID int64, tag string) (int64, error)
+	GetTagCounts(ctx context.Context, chatID int64) ([]models.TagCount, error)
+	NextReminderNum(ctx context.Context, chatID int64) (int, error)
+	GetUnnumberedReminders(ctx context.Context) ([]models.Reminder, error)
+	SetReminderNum(ctx context.Context, id string, num int) error
+	RescheduleReminder(ctx context.Context, id string, owner string, utcTime, originalTime time.Time, alerts []models.Alert) error
+	ReactivateReminder(ctx context.Context, id string, utcTime, originalTime time.Time, alerts []models.Alert) error
+	MarkReminderAsDelivered(ctx context.Context, id string, owner string, deliveredAt time.Time) error
+	ScheduleNag(ctx context.Context, id string, owner string, deliveredAt, next time.Time) error
+	DeferReminder(ctx context.Context, id string, until, from time.Time) error
+	AcknowledgeReminder(ctx context.Context, chatID int64, id string, at time.Time) (int64, error)
+	RescheduleReminders(ctx context.Context, reminders []models.Reminder) error
+	UpdateAlerts(ctx context.Context, id string, owner string, alerts []models.Alert, next time.Time) error
 	GetTimezone(ctx context.Context, chatID int64) (models.ChatTimezone, error)
-	UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error
-	AddTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error
+	UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error
+	AddTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error
+	SetZone(ctx context.Context, chatID int64, zone string) error
 	DeleteTimezone(ctx context.Context, chatID int64) error
-	SetUserPage(ctx context.Context, chatID int64, page int) error
-	GetUserPage(ctx context.Context, chatID int64) int
+	GetLegacyTimezones(ctx context.Context) ([]models.LegacyTimezone, error)
+	GetSettings(ctx context.Context, chatID int64) (models.ChatSettings, error)
+	SaveSettings(ctx context.Context, settings models.ChatSettings) error
+	GetDueDigests(ctx context.Context, now time.Time) ([]models.ChatSettings, error)
+	AdvanceDigest(ctx context.Context, chatID int64, from, next time.Time) (bool, error)
+	SuspendChat(ctx context.Context, chatID int64, at time.Time) (int64, error)
+	ResumeChat(ctx context.Context, chatID int64) (int64, error)
+	CountSuspendedReminders(ctx context.Context, chatID int64, now time.Time) (total, missed int, err error)
 }
 
 type RemindersStorage struct {
 	Reminders     *mongo.Collection
 	ChatTimezones *mongo.Collection
-	PageState     *mongo.Collection
+	Counters      *mongo.Collection
+	Settings      *mongo.Collection
 }
 
-func NewRemindersStorage(client *mongo.Client, dbname string, collectionnames []string) *RemindersStorage {
+// Collections - имена коллекций базы dbname, с которыми работает хранилище.
+type Collections struct {
+	Reminders string
+	Timezones string
+	// Counters - счетчики коротких номеров напоминаний по чатам.
+	Counters string
+	// Settings - настройки чатов: сводки, тихие часы.
+	Settings string
+}
+
+func NewRemindersStorage(client *mongo.Client, dbname string, collections Collections) *RemindersStorage {
 	return &RemindersStorage{
-		Reminders:     client.Database(dbname).Collection(collectionnames[0]),
-		ChatTimezones: client.Database(dbname).Collection(collectionnames[1]),
-		PageState:     client.Database(dbname).Collection(collectionnames[2]),
+		Reminders:     client.Database(dbname).Collection(collections.Reminders),
+		ChatTimezones: client.Database(dbname).Collection(collections.Timezones),
+		Counters:      client.Database(dbname).Collection(collections.Counters),
+		Settings:      client.Database(dbname).Collection(collections.Settings),
 	}
 }
 
+// EnsureIndexes создает индексы коллекции напоминаний. Индекс по chat_id, is_active и utc_time
+// нужен списку: он выбирает активные напоминания чата сразу в порядке срабатывания.
+// Индекс по is_active и utc_time - доставке, которая ищет наступившие напоминания всех чатов.
+func (r *RemindersStorage) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Reminders.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{
+			{Key: "chat_id", Value: 1},
+			{Key: "is_active", Value: 1},
+			{Key: "utc_time", Value: 1},
+		}},
+		{Keys: bson.D{
+			{Key: "is_active", Value: 1},
+			{Key: "utc_time", Value: 1},
+		}},
+	})
+	return err
+}
+
+// AddReminder сохраняет новое напоминание и выдает ему следующий номер в чате, если номера еще нет.
 func (r *RemindersStorage) AddReminder(ctx context.Context, reminder models.Reminder) error {
 	reminder.IsActive = true
+	if reminder.Num == 0 {
+		num, err := r.NextReminderNum(ctx, reminder.ChatID)
+		if err != nil {
+			return err
+		}
+		reminder.Num = num
+	}
 	_, err := r.Reminders.InsertOne(ctx, reminder)
 	return err
 }
-func (r *RemindersStorage) GetUpcomingReminders(ctx context.Context) ([]models.Reminder, error) {
-	now := time.Now().UTC()
+
+// GetUpcomingReminders возвращает активные напоминания, которые должны сработать не позже until,
+// в том числе просроченные, в порядке срабатывания.
+func (r *RemindersStorage) GetUpcomingReminders(ctx context.Context, until time.Time) ([]models.Reminder, error) {
 	filter := bson.M{
-		"time":      bson.M{"$lte": now.Add(60 * time.Second)},
+		"utc_time":  bson.M{"$lte": until.UTC()},
 		"is_active": true,
+		"suspended": bson.M{"$ne": true},
 	}
-	fmt.Println(filter)
-	cursor, err := r.Reminders.Find(ctx, filter)
+	opts := options.Find().SetSort(bson.D{{Key: "utc_time", Value: 1}})
+	cursor, err := r.Reminders.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -62,17 +135,136 @@ func (r *RemindersStorage) GetUpcomingReminders(ctx context.Context) ([]models.R
 	if err := cursor.All(ctx, &reminders); err != nil {
 		return nil, err
 	}
-	fmt.Println(reminders)
 	return reminders, nil
 }
 
-func (r *RemindersStorage) MarkReminderAsInactive(ctx context.Context, chatID int64, id string) (int64, error) {
+// ClaimDueReminder атомарно захватывает самое раннее наступившее напоминание для отправки репликой owner
+// до leaseUntil. Напоминания, которые уже отправляет другая реплика, пропускаются, пока не истечет их аренда:
+// так упавшая реплика не теряет напоминания, а две живые не отправляют одно и то же дважды.
+// false - захватывать нечего.
+func (r *RemindersStorage) ClaimDueReminder(ctx context.Context, owner string, now, leaseUntil time.Time) (models.Reminder, bool, error) {
+	filter := bson.M{
+		"is_active": true,
+		"utc_time":  bson.M{"$lte": now},
+		"suspended": bson.M{"$ne": true},
+		"$or": bson.A{
+			bson.M{"lease_until": bson.M{"$exists": false}},
+			bson.M{"lease_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"claimed_by": owner, "lease_until": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "utc_time", Value: 1}}).
+		SetReturnDocument(options.After)
+	var reminder models.Reminder
+	err := r.Reminders.FindOneAndUpdate(ctx, filter, update, opts).Decode(&reminder)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return reminder, false, nil
+	}
+	if err != nil {
+		return reminder, false, err
+	}
+	return reminder, true, nil
+}
+
+// withoutLease дополняет $unset полями аренды и неудачных попыток: срабатывание обработано,
+// и следующее может захватить любая реплика.
+func withoutLease(unset bson.M) bson.M {
+	unset["claimed_by"] = ""
+	unset["lease_until"] = ""
+	unset["attempts"] = ""
+	unset["last_error"] = ""
+	return unset
+}
+
+// claimedBy - фильтр записи результата отправки: напоминание все еще активно и захвачено репликой owner.
+// Если аренда истекла и напоминание перехватила другая реплика, результат записывает она, а запоздалая
+// запись прежней реплики не должна сбить ей счетчик попыток или перенести срабатывание второй раз.
+func claimedBy(oid primitive.ObjectID, owner string) bson.M {
+	return bson.M{"_id": oid, "is_active": true, "claimed_by": owner}
+}
+
+// ExtendLease продлевает аренду напоминания до until, если она все еще у реплики owner.
+// false - аренду уже перехватила другая реплика или напоминание обработано.
+func (r *RemindersStorage) ExtendLease(ctx context.Context, id string, owner string, until time.Time) (bool, error) {
 	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return 0, errors.New("invalid ID format")
+		return false, errors.New("invalid ID format")
+	}
+	filter := bson.M{"_id": oid, "claimed_by": owner}
+	changes, err := r.Reminders.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lease_until": until}})
+	if err != nil {
+		return false, err
+	}
+	return changes.MatchedCount > 0, nil
+}
+
+// ReleaseLease снимает аренду реплики owner с напоминания, которое она так и не отправила, чтобы
+// его сразу могла захватить любая реплика. Перехваченное другой репликой напоминание не трогается.
+func (r *RemindersStorage) ReleaseLease(ctx context.Context, id string, owner string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	filter := bson.M{"_id": oid, "claimed_by": owner}
+	_, err = r.Reminders.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"claimed_by": "", "lease_until": ""}})
+	return err
+}
+
+// RecordDeliveryFailure запоминает неудачную попытку отправки реплики owner и не дает захватить
+// напоминание раньше retryAt.
+func (r *RemindersStorage) RecordDeliveryFailure(ctx context.Context, id string, owner string, attempts int, lastError string, retryAt time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		// Повтор - та же аренда, только без хозяина: до retryAt напоминание не возьмет ни одна реплика
+		"$set":   bson.M{"attempts": attempts, "last_error": lastError, "lease_until": retryAt},
+		"$unset": bson.M{"claimed_by": ""},
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), update)
+	return err
+}
+
+// MarkReminderAsFailed снимает с активных напоминание, которое реплика owner так и не смогла отправить.
+func (r *RemindersStorage) MarkReminderAsFailed(ctx context.Context, id string, owner string, attempts int, lastError string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_active":  false,
+			"failed_at":  at,
+			"attempts":   attempts,
+			"last_error": lastError,
+		},
+		"$unset": bson.M{"claimed_by": "", "lease_until": ""},
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), update)
+	return err
+}
+
+// GetFailedReminders возвращает до limit последних напоминаний, которые не удалось отправить.
+func (r *RemindersStorage) GetFailedReminders(ctx context.Context, limit int) ([]models.Reminder, error) {
+	filter := bson.M{"failed_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.D{{Key: "failed_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.Reminders.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var reminders []models.Reminder
+	if err := cursor.All(ctx, &reminders); err != nil {
+		return nil, err
 	}
+	return reminders, nil
+}
+
+func (r *RemindersStorage) MarkReminderAsInactive(ctx context.Context, chatID int64, num int) (int64, error) {
 	filter := bson.M{
-		"_id":       oid,
+		"num":       num,
 		"chat_id":   chatID,
 		"is_active": true,
 	}
@@ -81,9 +273,46 @@ func (r *RemindersStorage) MarkReminderAsInactive(ctx context.Context, chatID in
 			"is_active": false,
 		},
 	}
-	changes, err := r.Reminders.UpdateOne(context.TODO(), filter, update)
+	changes, err := r.Reminders.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return changes.ModifiedCount, nil
+}
+
+// MarkRemindersAsInactiveByTag снимает с активных все напоминания чата с тегом tag и возвращает их число.
+func (r *RemindersStorage) MarkRemindersAsInactiveByTag(ctx context.Context, chatID int64, tag string) (int64, error) {
+	filter := bson.M{
+		"chat_id":   chatID,
+		"is_active": true,
+		"tags":      tag,
+	}
+	changes, err := r.Reminders.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"is_active": false}})
+	if err != nil {
+		return 0, err
+	}
+	return changes.ModifiedCount, nil
+}
+
+// GetTagCounts возвращает теги активных напоминаний чата с числом напоминаний, начиная с самых частых.
+func (r *RemindersStorage) GetTagCounts(ctx context.Context, chatID int64) ([]models.TagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"chat_id": chatID, "is_active": true}}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.Reminders.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
 
-	return changes.ModifiedCount, err
+	var tags []models.TagCount
+	if err := cursor.All(ctx, &tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
 }
 
 func (r *RemindersStorage) GetReminders(ctx context.Context, chatID int64) ([]models.Reminder, error) {
@@ -105,3 +334,327 @@ func (r *RemindersStorage) GetReminders(ctx context.Context, chatID int64) ([]mo
 	}
 	return reminders, nil
 }
+
+// CountReminders возвращает число активных напоминаний чата, подходящих под filter.
+func (r *RemindersStorage) CountReminders(ctx context.Context, chatID int64, filter models.ReminderFilter) (int, error) {
+	count, err := r.Reminders.CountDocuments(ctx, listFilter(chatID, filter))
+	return int(count), err
+}
+
+// GetRemindersPage возвращает limit активных напоминаний чата (все, если limit равен 0), подходящих под filter, пропустив первые skip,
+// начиная с ближайших. При одинаковом времени порядок задает _id, чтобы страницы не перемешивались.
+func (r *RemindersStorage) GetRemindersPage(ctx context.Context, chatID int64, filter models.ReminderFilter, skip, limit int) ([]models.Reminder, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "utc_time", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cursor, err := r.Reminders.Find(ctx, listFilter(chatID, filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reminders []models.Reminder
+	if err := cursor.All(ctx, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// listFilter переводит условия списка в запрос к коллекции напоминаний.
+func listFilter(chatID int64, filter models.ReminderFilter) bson.M {
+	query := bson.M{
+		"chat_id":   chatID,
+		"is_active": true,
+	}
+	wallClock := bson.M{}
+	if !filter.From.IsZero() {
+		wallClock["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		wallClock["$lt"] = filter.To
+	}
+	if len(wallClock) > 0 {
+		query["time"] = wallClock
+	}
+	if !filter.DueBefore.IsZero() {
+		query["utc_time"] = bson.M{"$lt": filter.DueBefore}
+	}
+	if filter.Query != "" {
+		query["action"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Query), Options: "i"}
+	}
+	if filter.Tag != "" {
+		query["tags"] = filter.Tag
+	}
+	return query
+}
+
+// GetReminder возвращает напоминание чата по ID, в том числе уже отправленное.
+func (r *RemindersStorage) GetReminder(ctx context.Context, chatID int64, id string) (models.Reminder, error) {
+	var reminder models.Reminder
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return reminder, errors.New("invalid ID format")
+	}
+	err = r.Reminders.FindOne(ctx, bson.M{"_id": oid, "chat_id": chatID}).Decode(&reminder)
+	return reminder, err
+}
+
+// GetReminderByNum возвращает напоминание чата по его короткому номеру.
+func (r *RemindersStorage) GetReminderByNum(ctx context.Context, chatID int64, num int) (models.Reminder, error) {
+	var reminder models.Reminder
+	err := r.Reminders.FindOne(ctx, bson.M{"chat_id": chatID, "num": num}).Decode(&reminder)
+	return reminder, err
+}
+
+// NextReminderNum атомарно выдает следующий номер напоминания в чате. Счетчик у каждого чата свой
+// и хранится в отдельной коллекции, номера не повторяются.
+func (r *RemindersStorage) NextReminderNum(ctx context.Context, chatID int64) (int, error) {
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.Counters.FindOneAndUpdate(ctx, bson.M{"_id": chatID}, bson.M{"$inc": bson.M{"seq": 1}}, opts).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+// GetUnnumberedReminders возвращает активные напоминания, созданные до появления номеров,
+// по порядку срабатывания.
+func (r *RemindersStorage) GetUnnumberedReminders(ctx context.Context) ([]models.Reminder, error) {
+	filter := bson.M{
+		"num":       bson.M{"$exists": false},
+		"is_active": true,
+	}
+	cursor, err := r.Reminders.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "utc_time", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reminders []models.Reminder
+	if err := cursor.All(ctx, &reminders); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// SetReminderNum присваивает номер напоминанию, у которого его еще нет.
+func (r *RemindersStorage) SetReminderNum(ctx context.Context, id string, num int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	filter := bson.M{"_id": oid, "num": bson.M{"$exists": false}}
+	_, err = r.Reminders.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"num": num}})
+	return err
+}
+
+// UpdateReminder перезаписывает текст, время и настройки активного напоминания чата.
+// Пустые необязательные поля удаляются из записи. Возвращает 0, если напоминание не найдено.
+func (r *RemindersStorage) UpdateReminder(ctx context.Context, reminder models.Reminder) (int64, error) {
+	oid, err := primitive.ObjectIDFromHex(reminder.ID)
+	if err != nil {
+		return 0, errors.New("invalid ID format")
+	}
+	filter := bson.M{
+		"_id":       oid,
+		"chat_id":   reminder.ChatID,
+		"is_active": true,
+	}
+	set := bson.M{
+		"action":   reminder.Action,
+		"utc_time": reminder.Time,
+		"time":     reminder.OriginalTime,
+	}
+	unset := bson.M{}
+	setOrUnset := func(field string, value any, empty bool) {
+		if empty {
+			unset[field] = ""
+		} else {
+			set[field] = value
+		}
+	}
+	setOrUnset("recurrence", reminder.Recurrence, reminder.Recurrence == nil)
+	setOrUnset("relative", reminder.Relative, !reminder.Relative)
+	setOrUnset("nag", reminder.Nag, reminder.Nag == nil)
+	setOrUnset("nag_count", reminder.NagCount, reminder.NagCount == 0)
+	setOrUnset("delivered_at", reminder.DeliveredAt, reminder.DeliveredAt == nil)
+	setOrUnset("alerts", reminder.Alerts, len(reminder.Alerts) == 0)
+	setOrUnset("tags", reminder.Tags, len(reminder.Tags) == 0)
+	setOrUnset("urgent", reminder.Urgent, !reminder.Urgent)
+	setOrUnset("deferred_from", reminder.DeferredFrom, reminder.DeferredFrom == nil)
+	// Новое время - новое срабатывание, его может отправить любая реплика
+	changes, err := r.Reminders.UpdateOne(ctx, filter, bson.M{"$set": set, "$unset": withoutLease(unset)})
+	if err != nil {
+		return 0, err
+	}
+	return changes.MatchedCount, nil
+}
+
+// RescheduleReminder переносит повторяющееся напоминание, которое только что отправила реплика owner,
+// на следующее срабатывание utcTime (time - то же по часам чата) с новыми предупреждениями alerts.
+// Удаленное тем временем напоминание так и остается удаленным, а перехваченное другой репликой
+// не трогается: его срабатывание обрабатывает она.
+func (r *RemindersStorage) RescheduleReminder(ctx context.Context, id string, owner string, utcTime, originalTime time.Time, alerts []models.Alert) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), nextFiringUpdate(bson.M{}, utcTime, originalTime, alerts))
+	return err
+}
+
+// ReactivateReminder назначает напоминанию новое срабатывание по просьбе пользователя и делает его
+// снова активным: так откладывается уже отправленное разовое напоминание и продолжается
+// повторяющееся после "Готово".
+func (r *RemindersStorage) ReactivateReminder(ctx context.Context, id string, utcTime, originalTime time.Time, alerts []models.Alert) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := nextFiringUpdate(bson.M{"is_active": true}, utcTime, originalTime, alerts)
+	_, err = r.Reminders.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	return err
+}
+
+// nextFiringUpdate дополняет set новым срабатыванием напоминания.
+func nextFiringUpdate(set bson.M, utcTime, originalTime time.Time, alerts []models.Alert) bson.M {
+	set["utc_time"] = utcTime
+	set["time"] = originalTime
+	if len(alerts) > 0 {
+		set["alerts"] = alerts
+	}
+	return bson.M{
+		"$set": set,
+		// Новое срабатывание начинается заново: еще не отправлено, не повторялось и не откладывалось
+		"$unset": withoutLease(bson.M{"delivered_at": "", "nag_count": "", "deferred_from": ""}),
+	}
+}
+
+// MarkReminderAsDelivered завершает разовое напоминание после последней отправки.
+// delivered_at не перезаписывается, если напоминание уже отправлялось раньше (при повторах).
+// Удаленное или подтвержденное тем временем напоминание не трогается, как и перехваченное
+// другой репликой после конца аренды owner.
+func (r *RemindersStorage) MarkReminderAsDelivered(ctx context.Context, id string, owner string, deliveredAt time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		"$set":   bson.M{"is_active": false},
+		"$min":   bson.M{"delivered_at": deliveredAt},
+		"$unset": withoutLease(bson.M{}),
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), update)
+	return err
+}
+
+// ScheduleNag оставляет отправленное напоминание активным и назначает повтор на next,
+// если пользователь до тех пор не нажмет "Готово". Если он успел нажать его или удалить
+// напоминание, пока оно отправлялось, или напоминание уже перехватила другая реплика,
+// повтор не назначается.
+func (r *RemindersStorage) ScheduleNag(ctx context.Context, id string, owner string, deliveredAt, next time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		"$set":   bson.M{"utc_time": next},
+		"$min":   bson.M{"delivered_at": deliveredAt},
+		"$inc":   bson.M{"nag_count": 1},
+		"$unset": withoutLease(bson.M{"deferred_from": ""}),
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), update)
+	return err
+}
+
+// DeferReminder откладывает срабатывание напоминания до until, запоминая, когда оно должно было сработать.
+func (r *RemindersStorage) DeferReminder(ctx context.Context, id string, until, from time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"utc_time":      until,
+			"deferred_from": from,
+		},
+		"$unset": withoutLease(bson.M{}),
+	}
+	_, err = r.Reminders.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	return err
+}
+
+// AcknowledgeReminder отмечает отправленное напоминание выполненным и снимает его с активных.
+// Возвращает 0, если напоминание не найдено, еще не было отправлено или уже не активно
+// (подтверждено раньше или удалено).
+func (r *RemindersStorage) AcknowledgeReminder(ctx context.Context, chatID int64, id string, at time.Time) (int64, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return 0, errors.New("invalid ID format")
+	}
+	filter := bson.M{
+		"_id":          oid,
+		"chat_id":      chatID,
+		"is_active":    true,
+		"delivered_at": bson.M{"$exists": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"acknowledged_at": at,
+			"is_active":       false,
+		},
+	}
+	changes, err := r.Reminders.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return changes.MatchedCount, nil
+}
+
+// RescheduleReminders одним запросом записывает новое время (utc_time и time) для нескольких напоминаний.
+func (r *RemindersStorage) RescheduleReminders(ctx context.Context, reminders []models.Reminder) error {
+	var writes []mongo.WriteModel
+	for _, reminder := range reminders {
+		oid, err := primitive.ObjectIDFromHex(reminder.ID)
+		if err != nil {
+			return errors.New("invalid ID format")
+		}
+		set := bson.M{
+			"utc_time": reminder.Time,
+			"time":     reminder.OriginalTime,
+		}
+		if len(reminder.Alerts) > 0 {
+			set["alerts"] = reminder.Alerts
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": oid}).
+			SetUpdate(bson.M{"$set": set}))
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err := r.Reminders.BulkWrite(ctx, writes)
+	return err
+}
+
+// UpdateAlerts сохраняет состояние предупреждений заранее и время следующего срабатывания
+// после отправки предупреждения репликой owner.
+func (r *RemindersStorage) UpdateAlerts(ctx context.Context, id string, owner string, alerts []models.Alert, next time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid ID format")
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"alerts":   alerts,
+			"utc_time": next,
+		},
+		"$unset": withoutLease(bson.M{"deferred_from": ""}),
+	}
+	_, err = r.Reminders.UpdateOne(ctx, claimedBy(oid, owner), update)
+	return err
+}
@@ -6,31 +6,48 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
 func TestStorage_AddReminder(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	counter := mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: int64(1)}, {Key: "seq", Value: 3}}})
 	mt.Run("successful insertion", func(mt *mtest.T) {
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 		reminder := models.Reminder{
 			IsActive: true,
 		}
-		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		mt.AddMockResponses(counter, mtest.CreateSuccessResponse())
 
 		err := repo.AddReminder(context.TODO(), reminder)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
+		assert.Equal(t, "findAndModify", mt.GetStartedEvent().CommandName)
+		inserted := mt.GetStartedEvent()
+		assert.Equal(t, "insert", inserted.CommandName)
+		doc := inserted.Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, int32(3), doc.Lookup("num").Int32())
+	})
+	mt.Run("CounterError", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "counter"}))
+
+		err := repo.AddReminder(context.TODO(), models.Reminder{})
+		assert.Error(t, err)
 	})
 	mt.Run("InsertError", func(mt *mtest.T) {
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 		reminder := models.Reminder{
 			IsActive: true,
+			Num:      1,
 		}
 		mockErr := mtest.WriteError{
 			Index:   0,
@@ -45,9 +62,58 @@ func TestStorage_AddReminder(t *testing.T) {
 	})
 }
 
+func TestStorage_NextReminderNum(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: int64(1)}, {Key: "seq", Value: 7}}}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		num, err := repo.NextReminderNum(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, num)
+	})
+}
+
+func TestStorage_GetUnnumberedReminders(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		oid := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: oid},
+			{Key: "chat_id", Value: int64(1)},
+			{Key: "action", Value: "test"},
+		}), mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.NextBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminders, err := repo.GetUnnumberedReminders(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Reminder{{ID: oid.Hex(), ChatID: 1, Action: "test"}}, reminders)
+	})
+}
+
+func TestStorage_SetReminderNum(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.SetReminderNum(context.Background(), "507f1f77bcf86cd799439011", 2)
+		assert.NoError(t, err)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.SetReminderNum(context.Background(), "5d799439011", 2)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
 func TestStorage_GetUpcomingReminders(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	mt.Run("error on find", func(mt *mtest.T) {
 		mockErr := mtest.WriteError{
 			Code:    12345,
@@ -58,7 +124,7 @@ func TestStorage_GetUpcomingReminders(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		_, err := repo.GetUpcomingReminders(context.Background())
+		_, err := repo.GetUpcomingReminders(context.Background(), time.Now())
 		if err == nil {
 			t.Fatal("expected error, got none")
 		}
@@ -77,7 +143,10 @@ func TestStorage_GetUpcomingReminders(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		result,  err := repo.GetUpcomingReminders(context.Background())
+		until := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+		result, err := repo.GetUpcomingReminders(context.Background(), until)
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, until, find.Lookup("filter", "utc_time", "$lte").Time().UTC())
 		if len(result) != 2 {
 			t.Fatalf("expected 2 reminders, got %d", len(result))
 		}
@@ -104,8 +173,8 @@ func TestStorage_GetUpcomingReminders(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		result,  err := repo.GetUpcomingReminders(context.Background())
-		if result != nil{
+		result, err := repo.GetUpcomingReminders(context.Background(), time.Now())
+		if result != nil {
 			t.Fatalf("unexepected result")
 		}
 		//assert.Equal(t, result, []models.Reminder{})
@@ -113,9 +182,200 @@ func TestStorage_GetUpcomingReminders(t *testing.T) {
 	})
 }
 
+func TestStorage_ClaimDueReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	lease := now.Add(2 * time.Minute)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+			{Key: "_id", Value: "507f1f77bcf86cd799439011"},
+			{Key: "chat_id", Value: int64(1)},
+			{Key: "action", Value: "Reminder 1"},
+			{Key: "claimed_by", Value: "replica-a"},
+			{Key: "lease_until", Value: lease},
+		}}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminder, ok, err := repo.ClaimDueReminder(context.Background(), "replica-a", now, lease)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "Reminder 1", reminder.Action)
+		assert.Equal(t, "replica-a", reminder.ClaimedBy)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(t, now, command.Lookup("query", "utc_time", "$lte").Time().UTC())
+		// Захватить можно только свободное напоминание или то, чья аренда истекла
+		or := command.Lookup("query", "$or").Array()
+		assert.Equal(t, false, or.Index(0).Value().Document().Lookup("lease_until", "$exists").Boolean())
+		assert.Equal(t, now, or.Index(1).Value().Document().Lookup("lease_until", "$lte").Time().UTC())
+		set := command.Lookup("update", "$set").Document()
+		assert.Equal(t, "replica-a", set.Lookup("claimed_by").StringValue())
+		assert.Equal(t, lease, set.Lookup("lease_until").Time().UTC())
+	})
+	mt.Run("NothingDue", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, ok, err := repo.ClaimDueReminder(context.Background(), "replica-a", now, lease)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "claim error"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, ok, err := repo.ClaimDueReminder(context.Background(), "replica-a", now, lease)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestStorage_ExtendLease(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	until := time.Date(2040, 12, 12, 12, 5, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		ok, err := repo.ExtendLease(context.Background(), "507f1f77bcf86cd799439011", "replica-a", until)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "replica-a", update.Lookup("q", "claimed_by").StringValue())
+		assert.Equal(t, until, update.Lookup("u", "$set", "lease_until").Time().UTC())
+	})
+	mt.Run("LeaseLost", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		ok, err := repo.ExtendLease(context.Background(), "507f1f77bcf86cd799439011", "replica-a", until)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "update failed"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.ExtendLease(context.Background(), "507f1f77bcf86cd799439011", "replica-a", until)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_ReleaseLease(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.ReleaseLease(context.Background(), "507f1f77bcf86cd799439011", "replica-a")
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "replica-a", update.Lookup("q", "claimed_by").StringValue())
+		unset := update.Lookup("u", "$unset").Document()
+		assert.NotNil(t, unset.Lookup("claimed_by").Value)
+		assert.NotNil(t, unset.Lookup("lease_until").Value)
+		// Счетчик неудачных попыток переживает остановку реплики
+		_, err = unset.LookupErr("attempts")
+		assert.Error(t, err)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.ReleaseLease(context.Background(), "bad", "replica-a")
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_RecordDeliveryFailure(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	retryAt := time.Date(2040, 12, 12, 12, 1, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RecordDeliveryFailure(context.Background(), "507f1f77bcf86cd799439011", "replica-a", 2, "502 Bad Gateway", retryAt)
+		assert.NoError(t, err)
+		op := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		// Запоздалая реплика, у которой напоминание уже перехватили, ничего не запишет
+		assert.Equal(t, "replica-a", op.Lookup("q", "claimed_by").StringValue())
+		assert.True(t, op.Lookup("q", "is_active").Boolean())
+		update := op.Lookup("u").Document()
+		assert.Equal(t, retryAt, update.Lookup("$set", "lease_until").Time().UTC())
+		assert.Equal(t, "502 Bad Gateway", update.Lookup("$set", "last_error").StringValue())
+		assert.Equal(t, int64(2), update.Lookup("$set", "attempts").AsInt64())
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RecordDeliveryFailure(context.Background(), "bad", "replica-a", 2, "502 Bad Gateway", retryAt)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_MarkReminderAsFailed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.MarkReminderAsFailed(context.Background(), "507f1f77bcf86cd799439011", "replica-a", 5, "502 Bad Gateway", now)
+		assert.NoError(t, err)
+		op := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "replica-a", op.Lookup("q", "claimed_by").StringValue())
+		set := op.Lookup("u", "$set").Document()
+		assert.Equal(t, false, set.Lookup("is_active").Boolean())
+		assert.Equal(t, now, set.Lookup("failed_at").Time().UTC())
+	})
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 12345, Message: "update failed"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.MarkReminderAsFailed(context.Background(), "507f1f77bcf86cd799439011", "replica-a", 5, "502 Bad Gateway", now)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_GetFailedReminders(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+				{Key: "chat_id", Value: int64(1)},
+				{Key: "action", Value: "Reminder 1"},
+				{Key: "attempts", Value: 5},
+				{Key: "last_error", Value: "502 Bad Gateway"},
+			}),
+			mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.NextBatch),
+		)
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminders, err := repo.GetFailedReminders(context.Background(), 20)
+		assert.NoError(t, err)
+		assert.Len(t, reminders, 1)
+		assert.Equal(t, 5, reminders[0].Attempts)
+		assert.Equal(t, "502 Bad Gateway", reminders[0].LastError)
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, int64(20), find.Lookup("limit").Int64())
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find error"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetFailedReminders(context.Background(), 20)
+		assert.Error(t, err)
+	})
+}
+
 func TestStorage_GetReminders(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	mt.Run("error on find", func(mt *mtest.T) {
 		mockErr := mtest.WriteError{
 			Code:    12345,
@@ -146,7 +406,7 @@ func TestStorage_GetReminders(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		result,  err := repo.GetReminders(context.Background(), chatID)
+		result, err := repo.GetReminders(context.Background(), chatID)
 		if len(result) != 2 {
 			t.Fatalf("expected 2 reminders, got %d", len(result))
 		}
@@ -173,18 +433,142 @@ func TestStorage_GetReminders(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		result,  err := repo.GetReminders(context.Background(), chatID)
-		if result != nil{
+		result, err := repo.GetReminders(context.Background(), chatID)
+		if result != nil {
 			t.Fatalf("unexepected result")
 		}
 		assert.Error(t, err)
 	})
-	
+
+}
+
+func TestStorage_CountReminders(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+			{Key: "n", Value: int32(7)},
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		count, err := repo.CountReminders(context.Background(), 1, models.ReminderFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, 7, count)
+	})
+}
+
+func TestStorage_GetRemindersPage(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		oid := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: oid},
+			{Key: "chat_id", Value: int64(1)},
+			{Key: "num", Value: 6},
+		}), mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.NextBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminders, err := repo.GetRemindersPage(context.Background(), 1, models.ReminderFilter{}, 5, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.Reminder{{ID: oid.Hex(), ChatID: 1, Num: 6}}, reminders)
+
+		find := mt.GetStartedEvent().Command
+		assert.Equal(t, int64(5), find.Lookup("skip").Int64())
+		assert.Equal(t, int64(5), find.Lookup("limit").Int64())
+		sort := find.Lookup("sort").Document()
+		assert.Equal(t, int32(1), sort.Lookup("utc_time").Int32())
+	})
+	mt.Run("FindError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetRemindersPage(context.Background(), 1, models.ReminderFilter{}, 0, 5)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_GetRemindersPageFilter(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.FirstBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		filter := models.ReminderFilter{From: from, To: from.AddDate(0, 1, 0), Query: "врач (стоматолог)"}
+
+		_, err := repo.GetRemindersPage(context.Background(), 1, filter, 0, 5)
+		assert.NoError(t, err)
+
+		query := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		wallClock := query.Lookup("time").Document()
+		assert.Equal(t, from, wallClock.Lookup("$gte").Time().UTC())
+		assert.Equal(t, from.AddDate(0, 1, 0), wallClock.Lookup("$lt").Time().UTC())
+		pattern, options := query.Lookup("action").Regex()
+		assert.Equal(t, `врач \(стоматолог\)`, pattern)
+		assert.Equal(t, "i", options)
+		_, hasDue := query.Lookup("utc_time").DocumentOK()
+		assert.False(t, hasDue)
+	})
+}
+
+func TestStorage_MarkRemindersAsInactiveByTag(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.MarkRemindersAsInactiveByTag(context.Background(), 1, "финансы")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), changes)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "финансы", update.Lookup("q", "tags").StringValue())
+		assert.True(t, update.Lookup("multi").Boolean())
+	})
+}
+
+func TestStorage_GetTagCounts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "работа"}, {Key: "count", Value: 3}},
+			bson.D{{Key: "_id", Value: "финансы"}, {Key: "count", Value: 1}},
+		), mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.NextBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		tags, err := repo.GetTagCounts(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.TagCount{{Tag: "работа", Count: 3}, {Tag: "финансы", Count: 1}}, tags)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "aggregate"}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetTagCounts(context.Background(), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_EnsureIndexes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.EnsureIndexes(context.Background())
+		assert.NoError(t, err)
+		keys := mt.GetStartedEvent().Command.Lookup("indexes").Array().Index(0).Value().Document().Lookup("key").Document()
+		elems, _ := keys.Elements()
+		assert.Equal(t, []string{"chat_id", "is_active", "utc_time"}, []string{elems[0].Key(), elems[1].Key(), elems[2].Key()})
+	})
 }
 
 func TestStorage_MarkReminderAsInactive(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	mt.Run("error on find", func(mt *mtest.T) {
 		mockErr := mtest.WriteError{
 			Code:    12345,
@@ -200,24 +584,352 @@ func TestStorage_MarkReminderAsInactive(t *testing.T) {
 		assert.Error(t, err)
 	})
 	mt.Run("OK", func(mt *mtest.T) {
-		id := "507f1f77bcf86cd799439011"
 		chatID := int64(1)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.MarkReminderAsInactive(context.Background(), chatID, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), changes)
+	})
+	mt.Run("error on update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "update failed"}))
+
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.MarkReminderAsInactive(context.Background(), 1, 3)
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), changes)
+	})
+}
+
+func TestStorage_RescheduleReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		id := "507f1f77bcf86cd799439011"
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		_, err := repo.MarkReminderAsInactive(context.Background(), chatID, id)
+		alerts := []models.Alert{{Lead: 30, At: next.Add(-30 * time.Minute)}}
+		err := repo.RescheduleReminder(context.Background(), id, "replica-a", next.Add(-30*time.Minute), next, alerts)
 		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		// Удаленное или перехваченное напоминание не должно вернуться к жизни
+		assert.Equal(t, true, update.Lookup("q", "is_active").Boolean())
+		assert.Equal(t, "replica-a", update.Lookup("q", "claimed_by").StringValue())
+		_, err = update.LookupErr("u", "$set", "is_active")
+		assert.Error(t, err)
+		assert.Equal(t, next.Add(-30*time.Minute), update.Lookup("u", "$set", "utc_time").Time().UTC())
+		assert.Equal(t, int64(30), update.Lookup("u", "$set", "alerts").Array().Index(0).Value().Document().Lookup("lead").AsInt64())
+	})
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		id := "507f1f77bcf86cd799439011"
+		mockErr := mtest.WriteError{
+			Code:    12345,
+			Message: "update failed",
+		}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mockErr))
+
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminder(context.Background(), id, "replica-a", next, next, nil)
+		assert.Error(t, err)
 	})
 	mt.Run("InvalidID", func(mt *mtest.T) {
-		id := "5d799439011"
-		chatID := int64(1)
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminder(context.Background(), "5d799439011", "replica-a", next, next, nil)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_ReactivateReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.ReactivateReminder(context.Background(), "507f1f77bcf86cd799439011", next, next, nil)
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, true, update.Lookup("u", "$set", "is_active").Boolean())
+		assert.Equal(t, next, update.Lookup("u", "$set", "utc_time").Time().UTC())
+		_, err = update.LookupErr("u", "$set", "alerts")
+		assert.Error(t, err)
+		_, err = update.LookupErr("q", "claimed_by")
+		assert.Error(t, err)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.ReactivateReminder(context.Background(), "5d799439011", next, next, nil)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_RescheduleReminders(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	reminders := []models.Reminder{
+		{ID: "507f1f77bcf86cd799439011", Time: next, OriginalTime: next.Add(3 * time.Hour)},
+		{ID: "507f1f77bcf86cd799439012", Time: next, OriginalTime: next.Add(3 * time.Hour)},
+	}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminders(context.Background(), reminders)
+		assert.NoError(t, err)
+	})
+	mt.Run("Empty", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminders(context.Background(), nil)
+		assert.NoError(t, err)
+	})
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    12345,
+			Message: "update failed",
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminders(context.Background(), reminders)
+		assert.Error(t, err)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.RescheduleReminders(context.Background(), []models.Reminder{{ID: "5d799439011"}})
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_GetReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	chatID := int64(1)
+	id := "507f1f77bcf86cd799439011"
+	mt.Run("OK", func(mt *mtest.T) {
+		oid, _ := primitive.ObjectIDFromHex(id)
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: oid},
+			{Key: "chat_id", Value: chatID},
+			{Key: "action", Value: "test"},
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminder, err := repo.GetReminder(context.Background(), chatID, id)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Reminder{ID: id, ChatID: chatID, Action: "test"}, reminder)
+	})
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.FirstBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetReminder(context.Background(), chatID, id)
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetReminder(context.Background(), chatID, "5d799439011")
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_GetReminderByNum(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	chatID := int64(1)
+	mt.Run("OK", func(mt *mtest.T) {
+		oid := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: oid},
+			{Key: "chat_id", Value: chatID},
+			{Key: "num", Value: 3},
+			{Key: "action", Value: "test"},
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		reminder, err := repo.GetReminderByNum(context.Background(), chatID, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Reminder{ID: oid.Hex(), ChatID: chatID, Num: 3, Action: "test"}, reminder)
+	})
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "testdb.testcol1", mtest.FirstBatch))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetReminderByNum(context.Background(), chatID, 3)
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+}
+
+func TestStorage_MarkReminderAsDelivered(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.MarkReminderAsDelivered(context.Background(), "507f1f77bcf86cd799439011", "replica-a", now)
+		assert.NoError(t, err)
+		q := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.True(t, q.Lookup("is_active").Boolean())
+		assert.Equal(t, "replica-a", q.Lookup("claimed_by").StringValue())
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.MarkReminderAsDelivered(context.Background(), "5d799439011", "replica-a", now)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
 
+func TestStorage_ScheduleNag(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		_, err := repo.MarkReminderAsInactive(context.Background(), chatID, id)
+		err := repo.ScheduleNag(context.Background(), "507f1f77bcf86cd799439011", "replica-a", now, now.Add(10*time.Minute))
+		assert.NoError(t, err)
+		q := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.True(t, q.Lookup("is_active").Boolean())
+		assert.Equal(t, "replica-a", q.Lookup("claimed_by").StringValue())
+	})
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    12345,
+			Message: "update failed",
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.ScheduleNag(context.Background(), "507f1f77bcf86cd799439011", "replica-a", now, now.Add(10*time.Minute))
 		assert.Error(t, err)
+	})
+}
+
+func TestStorage_DeferReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	from := time.Date(2040, 12, 12, 23, 30, 0, 0, time.UTC)
+	until := time.Date(2040, 12, 13, 7, 30, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.DeferReminder(context.Background(), "507f1f77bcf86cd799439011", until, from)
+		assert.NoError(t, err)
+		set := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set").Document()
+		assert.Equal(t, until, set.Lookup("utc_time").Time().UTC())
+		assert.Equal(t, from, set.Lookup("deferred_from").Time().UTC())
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.DeferReminder(context.Background(), "bad", until, from)
+		assert.Error(t, err)
+	})
+	mt.Run("UpdateError", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    12345,
+			Message: "update failed",
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.DeferReminder(context.Background(), "507f1f77bcf86cd799439011", until, from)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_AcknowledgeReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.AcknowledgeReminder(context.Background(), 1, "507f1f77bcf86cd799439011", now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), changes)
+		q := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.True(t, q.Lookup("is_active").Boolean())
+	})
+	mt.Run("NotDelivered", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.AcknowledgeReminder(context.Background(), 1, "507f1f77bcf86cd799439011", now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), changes)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.AcknowledgeReminder(context.Background(), 1, "5d799439011", now)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_UpdateAlerts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	alerts := []models.Alert{{Lead: 60, At: next.Add(-time.Hour), SentAt: &next}}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.UpdateAlerts(context.Background(), "507f1f77bcf86cd799439011", "replica-a", alerts, next)
+		assert.NoError(t, err)
+		q := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, "replica-a", q.Lookup("claimed_by").StringValue())
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		err := repo.UpdateAlerts(context.Background(), "5d799439011", "replica-a", alerts, next)
+		assert.Equal(t, err, errors.New("invalid ID format"))
+	})
+}
+
+func TestStorage_UpdateReminder(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2040, 12, 12, 12, 0, 0, 0, time.UTC)
+	reminder := models.Reminder{ID: "507f1f77bcf86cd799439011", ChatID: 1, Action: "test", Time: next, OriginalTime: next}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.UpdateReminder(context.Background(), reminder)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), changes)
+	})
+	mt.Run("NotFound", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		changes, err := repo.UpdateReminder(context.Background(), reminder)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), changes)
+	})
+	mt.Run("InvalidID", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.UpdateReminder(context.Background(), models.Reminder{ID: "5d799439011"})
 		assert.Equal(t, err, errors.New("invalid ID format"))
 	})
 }
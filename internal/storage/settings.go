@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"JillBot/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetSettings возвращает настройки чата. Если чат ничего не настраивал, настройки пустые.
+func (r *RemindersStorage) GetSettings(ctx context.Context, chatID int64) (models.ChatSettings, error) {
+	settings := models.ChatSettings{ChatID: chatID}
+	err := r.Settings.FindOne(ctx, bson.M{"chat_id": chatID}).Decode(&settings)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return settings, nil
+	}
+	return settings, err
+}
+
+// SaveSettings записывает настройки чата целиком, создавая документ при первом сохранении.
+func (r *RemindersStorage) SaveSettings(ctx context.Context, settings models.ChatSettings) error {
+	filter := bson.M{"chat_id": settings.ChatID}
+	_, err := r.Settings.ReplaceOne(ctx, filter, settings, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetDueDigests возвращает настройки чатов, которым пора прислать сводку на день.
+func (r *RemindersStorage) GetDueDigests(ctx context.Context, now time.Time) ([]models.ChatSettings, error) {
+	filter := bson.M{
+		"digest.enabled": true,
+		"digest.next":    bson.M{"$lte": now},
+		"suspended_at":   bson.M{"$exists": false},
+	}
+	cursor, err := r.Settings.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var settings []models.ChatSettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// AdvanceDigest переносит сводку чата, назначенную на from, на next. false - сводку уже перенесла
+// другая реплика бота, и отправит ее тоже она.
+func (r *RemindersStorage) AdvanceDigest(ctx context.Context, chatID int64, from, next time.Time) (bool, error) {
+	filter := bson.M{"chat_id": chatID, "digest.next": from}
+	changes, err := r.Settings.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"digest.next": next}})
+	if err != nil {
+		return false, err
+	}
+	return changes.MatchedCount > 0, nil
+}
+
+// SuspendChat отмечает чат недоступным с момента at и ставит на паузу его активные напоминания.
+// Возвращает, сколько напоминаний поставлено на паузу.
+func (r *RemindersStorage) SuspendChat(ctx context.Context, chatID int64, at time.Time) (int64, error) {
+	_, err := r.Settings.UpdateOne(ctx, bson.M{"chat_id": chatID},
+		bson.M{"$set": bson.M{"suspended_at": at}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
+	update := bson.M{
+		"$set":   bson.M{"suspended": true},
+		"$unset": withoutLease(bson.M{}),
+	}
+	changes, err := r.Reminders.UpdateMany(ctx, bson.M{"chat_id": chatID, "is_active": true}, update)
+	if err != nil {
+		return 0, err
+	}
+	return changes.ModifiedCount, nil
+}
+
+// ResumeChat снимает с чата отметку о недоступности и возвращает его напоминания в работу.
+// Пропущенные за время паузы напоминания сразу становятся наступившими.
+func (r *RemindersStorage) ResumeChat(ctx context.Context, chatID int64) (int64, error) {
+	_, err := r.Settings.UpdateOne(ctx, bson.M{"chat_id": chatID}, bson.M{"$unset": bson.M{"suspended_at": ""}})
+	if err != nil {
+		return 0, err
+	}
+	changes, err := r.Reminders.UpdateMany(ctx, bson.M{"chat_id": chatID, "suspended": true},
+		bson.M{"$unset": bson.M{"suspended": ""}})
+	if err != nil {
+		return 0, err
+	}
+	return changes.ModifiedCount, nil
+}
+
+// CountSuspendedReminders считает напоминания чата на паузе и сколько из них уже пропущено к now.
+func (r *RemindersStorage) CountSuspendedReminders(ctx context.Context, chatID int64, now time.Time) (total, missed int, err error) {
+	filter := bson.M{"chat_id": chatID, "is_active": true, "suspended": true}
+	all, err := r.Reminders.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	filter["utc_time"] = bson.M{"$lte": now}
+	due, err := r.Reminders.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(all), int(due), nil
+}
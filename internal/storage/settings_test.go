@@ -0,0 +1,217 @@
+package storage_test
+
+import (
+	"JillBot/internal/models"
+	"JillBot/internal/storage"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestStorage_GetSettings(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	next := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "testdb.testcol4", mtest.FirstBatch, bson.D{
+			{Key: "chat_id", Value: int64(1)},
+			{Key: "digest", Value: bson.D{
+				{Key: "enabled", Value: true},
+				{Key: "minute", Value: 480},
+				{Key: "next", Value: next},
+			}},
+		}))
+
+		settings, err := repo.GetSettings(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ChatSettings{
+			ChatID: 1,
+			Digest: &models.DigestSettings{Enabled: true, Minute: 480, Next: next},
+		}, settings)
+	})
+	mt.Run("NotFound", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "testdb.testcol4", mtest.FirstBatch))
+
+		settings, err := repo.GetSettings(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ChatSettings{ChatID: 1}, settings)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find error"}))
+
+		_, err := repo.GetSettings(context.TODO(), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_SaveSettings(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	settings := models.ChatSettings{ChatID: 1, Digest: &models.DigestSettings{Enabled: true, Minute: 480}}
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		err := repo.SaveSettings(context.TODO(), settings)
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command
+		assert.Equal(t, "testcol4", update.Lookup("update").StringValue())
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 1, Message: "replace error"}))
+
+		err := repo.SaveSettings(context.TODO(), settings)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_GetDueDigests(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		first := mtest.CreateCursorResponse(1, "testdb.testcol4", mtest.FirstBatch, bson.D{
+			{Key: "chat_id", Value: int64(1)},
+			{Key: "digest", Value: bson.D{{Key: "enabled", Value: true}, {Key: "minute", Value: 480}, {Key: "next", Value: now}}},
+		})
+		killCursors := mtest.CreateCursorResponse(0, "testdb.testcol4", mtest.NextBatch)
+		mt.AddMockResponses(first, killCursors)
+
+		settings, err := repo.GetDueDigests(context.TODO(), now)
+		assert.NoError(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, int64(1), settings[0].ChatID)
+		assert.Equal(t, 480, settings[0].Digest.Minute)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "find error"}))
+
+		_, err := repo.GetDueDigests(context.TODO(), now)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_AdvanceDigest(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	from := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	next := time.Date(2025, 1, 17, 5, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+
+		ok, err := repo.AdvanceDigest(context.TODO(), 1, from, next)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, from, update.Lookup("q", "digest.next").Time().UTC())
+	})
+	mt.Run("AdvancedByOtherReplica", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		ok, err := repo.AdvanceDigest(context.TODO(), 1, from, next)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 1, Message: "update error"}))
+
+		_, err := repo.AdvanceDigest(context.TODO(), 1, from, next)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_SuspendChat(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	at := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}),
+		)
+
+		suspended, err := repo.SuspendChat(context.TODO(), 1, at)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), suspended)
+		settings := mt.GetStartedEvent().Command
+		assert.Equal(t, "testcol4", settings.Lookup("update").StringValue())
+		reminders := mt.GetStartedEvent().Command
+		assert.Equal(t, "testcol1", reminders.Lookup("update").StringValue())
+		update := reminders.Lookup("updates").Array().Index(0).Value().Document()
+		assert.True(t, update.Lookup("u", "$set", "suspended").Boolean())
+		_, err = update.LookupErr("u", "$unset", "lease_until")
+		assert.NoError(t, err)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 1, Message: "update error"}))
+
+		_, err := repo.SuspendChat(context.TODO(), 1, at)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_ResumeChat(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}),
+		)
+
+		resumed, err := repo.ResumeChat(context.TODO(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), resumed)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 1, Message: "update error"}),
+		)
+
+		_, err := repo.ResumeChat(context.TODO(), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_CountSuspendedReminders(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	now := time.Date(2025, 1, 16, 5, 0, 0, 0, time.UTC)
+	mt.Run("OK", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(5)}}),
+			mtest.CreateCursorResponse(1, "testdb.testcol1", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(2)}}),
+		)
+
+		total, missed, err := repo.CountSuspendedReminders(context.TODO(), 1, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.Equal(t, 2, missed)
+	})
+	mt.Run("Error", func(mt *mtest.T) {
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "count error"}))
+
+		_, _, err := repo.CountSuspendedReminders(context.TODO(), 1, now)
+		assert.Error(t, err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (r *RemindersStorage) GetTimezone(ctx context.Context, chatID int64) (models.ChatTimezone, error) {
@@ -15,23 +16,24 @@ func (r *RemindersStorage) GetTimezone(ctx context.Context, chatID int64) (model
 	}
 	return tz, nil
 }
-func (r *RemindersStorage) AddTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error {
+func (r *RemindersStorage) AddTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error {
 	tz := models.ChatTimezone{
-		ChatID:   chatID,
-		Latitude: lat,
+		ChatID:    chatID,
+		Latitude:  lat,
 		Longitude: long,
-		Diff_hour: diffhour,
+		Zone:      zone,
 	}
 	_, err := r.ChatTimezones.InsertOne(ctx, tz)
 	return err
 }
-func (r *RemindersStorage) UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, diffhour int) error {
+func (r *RemindersStorage) UpdateTimezone(ctx context.Context, chatID int64, lat, long float64, zone string) error {
 	updateTZ := bson.M{
 		"$set": bson.M{
-			"lat": lat,
+			"lat":  lat,
 			"long": long,
-			"diff_hour": diffhour,
+			"zone": zone,
 		},
+		"$unset": bson.M{"diff_hour": ""},
 	}
 	filter := bson.M{"chat_id": chatID}
 	_, err := r.ChatTimezones.UpdateOne(context.TODO(), filter, updateTZ)
@@ -41,8 +43,39 @@ func (r *RemindersStorage) UpdateTimezone(ctx context.Context, chatID int64, lat
 	return nil
 }
 
+// SetZone сохраняет часовой пояс, выбранный вручную. Координат у такой записи нет,
+// поэтому старые удаляются, а если записи для чата не было - она создается.
+func (r *RemindersStorage) SetZone(ctx context.Context, chatID int64, zone string) error {
+	updateTZ := bson.M{
+		"$set":   bson.M{"zone": zone},
+		"$unset": bson.M{"lat": "", "long": "", "diff_hour": ""},
+	}
+	filter := bson.M{"chat_id": chatID}
+	_, err := r.ChatTimezones.UpdateOne(ctx, filter, updateTZ, options.Update().SetUpsert(true))
+	return err
+}
+
 func (r *RemindersStorage) DeleteTimezone(ctx context.Context, chatID int64) error {
 	filter := bson.M{"chat_id": chatID}
 	_, err := r.ChatTimezones.DeleteOne(ctx, filter)
 	return err
 }
+
+// GetLegacyTimezones возвращает записи, сохраненные до перехода на IANA-зоны: с diff_hour и без zone.
+func (r *RemindersStorage) GetLegacyTimezones(ctx context.Context) ([]models.LegacyTimezone, error) {
+	filter := bson.M{
+		"diff_hour": bson.M{"$exists": true},
+		"zone":      bson.M{"$exists": false},
+	}
+	cursor, err := r.ChatTimezones.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var timezones []models.LegacyTimezone
+	if err := cursor.All(ctx, &timezones); err != nil {
+		return nil, err
+	}
+	return timezones, nil
+}
@@ -14,7 +14,7 @@ import (
 
 func TestStorage_GetTimezone(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	mt.Run("OK", func(mt *mtest.T) {
 		chatID := int64(1)
 		wantResp := models.ChatTimezone{ChatID: chatID}
@@ -47,17 +47,17 @@ func TestStorage_GetTimezone(t *testing.T) {
 
 func TestStorage_AddTimezone(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	chatID := int64(1)
 	lat := 0.0
 	long := 0.0
-	diffhour := 0
+	zone := "Europe/Berlin"
 	mt.Run("OK", func(mt *mtest.T) {
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 
-		err := repo.AddTimezone(context.TODO(), chatID, lat, long, diffhour)
+		err := repo.AddTimezone(context.TODO(), chatID, lat, long, zone)
 		assert.NoError(t, err)
 	})
 	mt.Run("InsertError", func(mt *mtest.T) {
@@ -69,7 +69,7 @@ func TestStorage_AddTimezone(t *testing.T) {
 		}
 
 		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mockErr))
-		err := repo.AddTimezone(context.TODO(), chatID, lat, long, diffhour)
+		err := repo.AddTimezone(context.TODO(), chatID, lat, long, zone)
 
 		assert.Error(t, err)
 	})
@@ -77,11 +77,11 @@ func TestStorage_AddTimezone(t *testing.T) {
 
 func TestStorage_UpdateTimezone(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	chatID := int64(1)
 	lat := 0.0
 	long := 0.0
-	diffhour := 0
+	zone := "Europe/Berlin"
 	mt.Run("error on find", func(mt *mtest.T) {
 		mockErr := mtest.WriteError{
 			Code:    12345,
@@ -92,19 +92,41 @@ func TestStorage_UpdateTimezone(t *testing.T) {
 
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
 
-		err := repo.UpdateTimezone(context.Background(), chatID, lat, long, diffhour)
+		err := repo.UpdateTimezone(context.Background(), chatID, lat, long, zone)
 		assert.Error(t, err)
 	})
 	mt.Run("OK", func(mt *mtest.T) {
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
-		err := repo.UpdateTimezone(context.Background(), chatID, lat, long, diffhour)
+		err := repo.UpdateTimezone(context.Background(), chatID, lat, long, zone)
 		assert.NoError(t, err)
 	})
 }
+func TestStorage_SetZone(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	chatID := int64(1)
+	zone := "Europe/Moscow"
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		err := repo.SetZone(context.Background(), chatID, zone)
+		assert.NoError(t, err)
+	})
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    12345,
+			Message: "update failed",
+		}))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+		err := repo.SetZone(context.Background(), chatID, zone)
+		assert.Error(t, err)
+	})
+}
+
 func TestStorage_DeleteTimezone(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
-	testcollection := []string{"testcol1", "testcol2", "testcol3"}
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
 	chatID := int64(1)
 	mt.Run("error on find", func(mt *mtest.T) {
 		mockErr := mtest.WriteError{
@@ -123,3 +145,33 @@ func TestStorage_DeleteTimezone(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestStorage_GetLegacyTimezones(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	testcollection := storage.Collections{Reminders: "testcol1", Timezones: "testcol2", Counters: "testcol3", Settings: "testcol4"}
+	mt.Run("OK", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "testdb.testcol2", mtest.FirstBatch, bson.D{
+				{Key: "chat_id", Value: int64(1)},
+				{Key: "diff_hour", Value: 3},
+			}),
+			mtest.CreateCursorResponse(0, "testdb.testcol2", mtest.NextBatch),
+		)
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		timezones, err := repo.GetLegacyTimezones(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, []models.LegacyTimezone{{ChatID: 1, DiffHour: 3}}, timezones)
+	})
+	mt.Run("FindError", func(mt *mtest.T) {
+		mockErr := mtest.WriteError{
+			Code:    12345,
+			Message: "find failed",
+		}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mockErr))
+		repo := storage.NewRemindersStorage(mt.Client, "testdb", testcollection)
+
+		_, err := repo.GetLegacyTimezones(context.TODO())
+		assert.Error(t, err)
+	})
+}
@@ -10,40 +10,40 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
-// MockTimeDiffGetter is a mock of TimeDiffGetter interface.
-type MockTimeDiffGetter struct {
+// MockZoneGetter is a mock of ZoneGetter interface.
+type MockZoneGetter struct {
 	ctrl     *gomock.Controller
-	recorder *MockTimeDiffGetterMockRecorder
+	recorder *MockZoneGetterMockRecorder
 }
 
-// MockTimeDiffGetterMockRecorder is the mock recorder for MockTimeDiffGetter.
-type MockTimeDiffGetterMockRecorder struct {
-	mock *MockTimeDiffGetter
+// MockZoneGetterMockRecorder is the mock recorder for MockZoneGetter.
+type MockZoneGetterMockRecorder struct {
+	mock *MockZoneGetter
 }
 
-// NewMockTimeDiffGetter creates a new mock instance.
-func NewMockTimeDiffGetter(ctrl *gomock.Controller) *MockTimeDiffGetter {
-	mock := &MockTimeDiffGetter{ctrl: ctrl}
-	mock.recorder = &MockTimeDiffGetterMockRecorder{mock}
+// NewMockZoneGetter creates a new mock instance.
+func NewMockZoneGetter(ctrl *gomock.Controller) *MockZoneGetter {
+	mock := &MockZoneGetter{ctrl: ctrl}
+	mock.recorder = &MockZoneGetterMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockTimeDiffGetter) EXPECT() *MockTimeDiffGetterMockRecorder {
+func (m *MockZoneGetter) EXPECT() *MockZoneGetterMockRecorder {
 	return m.recorder
 }
 
-// GetTimeDiff mocks base method.
-func (m *MockTimeDiffGetter) GetTimeDiff(lat, lon float64) (int, error) {
+// GetZone mocks base method.
+func (m *MockZoneGetter) GetZone(lat, lon float64) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTimeDiff", lat, lon)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetZone", lat, lon)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTimeDiff indicates an expected call of GetTimeDiff.
-func (mr *MockTimeDiffGetterMockRecorder) GetTimeDiff(lat, lon interface{}) *gomock.Call {
+// GetZone indicates an expected call of GetZone.
+func (mr *MockZoneGetterMockRecorder) GetZone(lat, lon interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeDiff", reflect.TypeOf((*MockTimeDiffGetter)(nil).GetTimeDiff), lat, lon)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetZone", reflect.TypeOf((*MockZoneGetter)(nil).GetZone), lat, lon)
 }
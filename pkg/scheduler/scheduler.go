@@ -0,0 +1,166 @@
+// Package scheduler будит доставку точно к сроку срабатывания.
+//
+// Планировщик держит в памяти min-кучу ближайших сроков и спит до первого из них, а не опрашивает
+// базу раз в минуту. Куча регулярно пополняется из хранилища на Horizon вперед, поэтому переживает
+// перезапуск и правки, о которых ей не сообщили. Сроки, о которых известно сразу (новое напоминание),
+// добавляются через Schedule. Сама куча хранит только время: что именно пора отправить, решает
+// функция доставки, поэтому устаревший срок (напоминание удалили или перенесли) лишь будит ее зря.
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Значения по умолчанию для New.
+const (
+	// DefaultRefill - как часто сверять кучу с хранилищем.
+	DefaultRefill = 30 * time.Second
+	// DefaultHorizon - на сколько вперед загружать сроки. Больше Refill, чтобы срок успел попасть
+	// в кучу хотя бы одной выборкой до наступления.
+	DefaultHorizon = 2 * time.Minute
+	// DefaultRetry - пауза перед повторной выборкой, если хранилище вернуло ошибку.
+	DefaultRetry = 5 * time.Second
+)
+
+// LoadFunc возвращает сроки срабатывания не позже until, в том числе уже наступившие.
+type LoadFunc func(ctx context.Context, until time.Time) ([]time.Time, error)
+
+// FireFunc доставляет все, что пора доставить к текущему моменту.
+type FireFunc func(ctx context.Context)
+
+type Scheduler struct {
+	Refill  time.Duration
+	Horizon time.Duration
+	Retry   time.Duration
+
+	mu     sync.Mutex
+	queue  dueQueue
+	queued map[time.Time]bool
+	wake   chan struct{}
+}
+
+func New() *Scheduler {
+	return &Scheduler{
+		Refill:  DefaultRefill,
+		Horizon: DefaultHorizon,
+		Retry:   DefaultRetry,
+		queued:  make(map[time.Time]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Schedule добавляет срок срабатывания. Одинаковые сроки хранятся один раз.
+// Безопасно вызывать из любой горутины, в том числе до Run.
+func (s *Scheduler) Schedule(at time.Time) {
+	at = at.UTC()
+	s.mu.Lock()
+	if s.queued[at] {
+		s.mu.Unlock()
+		return
+	}
+	s.queued[at] = true
+	heap.Push(&s.queue, at)
+	earliest := s.queue[0].Equal(at)
+	s.mu.Unlock()
+	if earliest {
+		// Run мог уснуть до более позднего срока - будим, чтобы пересчитать таймер
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Len возвращает число сроков в куче.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Run вызывает fire, как только наступает ближайший срок, и пополняет кучу через load каждые Refill.
+// Работает, пока не отменен ctx.
+func (s *Scheduler) Run(ctx context.Context, load LoadFunc, fire FireFunc) {
+	var nextRefill time.Time
+	for {
+		now := time.Now()
+		if !now.Before(nextRefill) {
+			nextRefill = now.Add(s.Refill)
+			if err := s.refill(ctx, load, now); err != nil {
+				log.Printf("Ошибка при получении напоминаний: %v", err)
+				nextRefill = now.Add(s.Retry)
+			}
+		}
+		if s.popDue(now) {
+			fire(ctx)
+			continue
+		}
+
+		wait := nextRefill.Sub(now)
+		if next, ok := s.peek(); ok && next.Sub(now) < wait {
+			wait = next.Sub(now)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) refill(ctx context.Context, load LoadFunc, now time.Time) error {
+	times, err := load(ctx, now.Add(s.Horizon))
+	if err != nil {
+		return err
+	}
+	for _, at := range times {
+		s.Schedule(at)
+	}
+	return nil
+}
+
+// popDue убирает из кучи все наступившие сроки и сообщает, были ли такие.
+func (s *Scheduler) popDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := false
+	for s.queue.Len() > 0 && !s.queue[0].After(now) {
+		at := heap.Pop(&s.queue).(time.Time)
+		delete(s.queued, at)
+		due = true
+	}
+	return due
+}
+
+func (s *Scheduler) peek() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.queue.Len() == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0], true
+}
+
+// dueQueue - min-куча сроков для container/heap.
+type dueQueue []time.Time
+
+func (q dueQueue) Len() int           { return len(q) }
+func (q dueQueue) Less(i, j int) bool { return q[i].Before(q[j]) }
+func (q dueQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *dueQueue) Push(x any) { *q = append(*q, x.(time.Time)) }
+
+func (q *dueQueue) Pop() any {
+	old := *q
+	at := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return at
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fired возвращает FireFunc, которая пишет моменты вызовов в канал.
+func fired() (FireFunc, chan time.Time) {
+	calls := make(chan time.Time, 10)
+	return func(ctx context.Context) { calls <- time.Now() }, calls
+}
+
+func noLoad(ctx context.Context, until time.Time) ([]time.Time, error) { return nil, nil }
+
+func TestScheduler_Schedule(t *testing.T) {
+	s := New()
+	at := time.Date(2040, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.Schedule(at)
+	s.Schedule(at.In(time.FixedZone("MSK", 3*60*60)))
+	s.Schedule(at.Add(-time.Second))
+	assert.Equal(t, 2, s.Len())
+	next, ok := s.peek()
+	assert.True(t, ok)
+	assert.Equal(t, at.Add(-time.Second), next)
+
+	assert.False(t, s.popDue(at.Add(-2*time.Second)))
+	assert.True(t, s.popDue(at))
+	assert.Equal(t, 0, s.Len())
+	// Сработавший срок можно запланировать снова
+	s.Schedule(at)
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestScheduler_RunFiresOnTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fire, calls := fired()
+	s := New()
+	due := time.Now().Add(100 * time.Millisecond)
+	s.Schedule(due)
+	go s.Run(ctx, noLoad, fire)
+
+	select {
+	case at := <-calls:
+		assert.False(t, at.Before(due))
+		assert.WithinDuration(t, due, at, 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("не сработало")
+	}
+}
+
+func TestScheduler_ScheduleWakesRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fire, calls := fired()
+	s := New()
+	s.Schedule(time.Now().Add(time.Hour))
+	go s.Run(ctx, noLoad, fire)
+
+	// Run уже спит до срока через час, а новый срок ближе
+	time.Sleep(20 * time.Millisecond)
+	due := time.Now().Add(50 * time.Millisecond)
+	s.Schedule(due)
+	select {
+	case at := <-calls:
+		assert.WithinDuration(t, due, at, 50*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("не сработало")
+	}
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestScheduler_Refill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fire, calls := fired()
+	s := New()
+	s.Horizon = time.Minute
+	var untilSeen atomic.Value
+	load := func(ctx context.Context, until time.Time) ([]time.Time, error) {
+		untilSeen.Store(until)
+		// Просроченное напоминание из базы доставляется сразу
+		return []time.Time{time.Now().Add(-time.Minute)}, nil
+	}
+	start := time.Now()
+	go s.Run(ctx, load, fire)
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("не сработало")
+	}
+	assert.WithinDuration(t, start.Add(time.Minute), untilSeen.Load().(time.Time), 100*time.Millisecond)
+}
+
+func TestScheduler_RefillErrorRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fire, calls := fired()
+	s := New()
+	s.Retry = 20 * time.Millisecond
+	var loads atomic.Int32
+	load := func(ctx context.Context, until time.Time) ([]time.Time, error) {
+		if loads.Add(1) < 3 {
+			return nil, errors.New("find error")
+		}
+		return []time.Time{time.Now()}, nil
+	}
+	go s.Run(ctx, load, fire)
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("не сработало")
+	}
+	// Без паузы между попытками их было бы намного больше
+	assert.Equal(t, int32(3), loads.Load())
+}
+
+func TestScheduler_RunStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		New().Run(ctx, noLoad, func(ctx context.Context) {})
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run не остановился")
+	}
+}
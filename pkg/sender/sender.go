@@ -0,0 +1,321 @@
+// Package sender отправляет сообщения в Telegram, не превышая его ограничений: не больше GlobalRate
+// сообщений в секунду всего и не больше ChatRate в один чат.
+//
+// Сообщения ставятся в очередь через Enqueue, у каждого чата очередь своя. Run выбирает чат, которому
+// раньше всех можно писать, ждет, пока освободится место в его ведре токенов и в общем, и отдает сообщение
+// одному из Workers. Так всплеск напоминаний на 09:00 растягивается на несколько секунд, а не упирается
+// в ответы 429, и десяток напоминаний одного чата не задерживает остальные чаты.
+//
+// Состояние очереди публикуется через expvar в переменной "sender" (по HTTP - /debug/vars).
+package sender
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math"
+	"sync"
+	"time"
+)
+
+// Значения по умолчанию для New - ограничения Telegram для ботов.
+const (
+	// DefaultGlobalRate - сообщений в секунду во все чаты вместе.
+	DefaultGlobalRate = 30
+	// DefaultChatRate - сообщений в секунду в один чат.
+	DefaultChatRate = 1
+	// DefaultWorkers - сколько сообщений отправляется одновременно. Отправка занимает сотни миллисекунд,
+	// поэтому одна горутина не успела бы за GlobalRate.
+	DefaultWorkers = 8
+)
+
+var (
+	metrics = expvar.NewMap("sender")
+	// queueDepth - сообщений в очереди, queueDepthMax - наибольшая очередь с запуска.
+	queueDepth    = new(expvar.Int)
+	queueDepthMax = new(expvar.Int)
+	// chatsWaiting - чатов, у которых есть сообщения в очереди.
+	chatsWaiting = new(expvar.Int)
+	sent         = new(expvar.Int)
+	failed       = new(expvar.Int)
+	skipped      = new(expvar.Int)
+	// waitMs - суммарное время сообщений в очереди; деленное на sent и failed - средняя задержка.
+	waitMs = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("queue_depth", queueDepth)
+	metrics.Set("queue_depth_max", queueDepthMax)
+	metrics.Set("chats_waiting", chatsWaiting)
+	metrics.Set("sent", sent)
+	metrics.Set("failed", failed)
+	metrics.Set("skipped", skipped)
+	metrics.Set("wait_ms", waitMs)
+}
+
+// ErrSkipped возвращает SendFunc, если сообщение, пока стояло в очереди, стало ненужным и не отправлялось.
+var ErrSkipped = errors.New("sender: message skipped")
+
+// ErrStopped получают DoneFunc сообщений, которые остались в очереди, когда Run остановился.
+var ErrStopped = errors.New("sender: stopped")
+
+// SendFunc отправляет одно сообщение.
+type SendFunc func() error
+
+// DoneFunc получает результат отправки. Вызывается из горутины отправки.
+type DoneFunc func(err error)
+
+// Sender - очередь исходящих сообщений. Поля с ограничениями меняются только до первого Enqueue.
+type Sender struct {
+	GlobalRate float64
+	ChatRate   float64
+	Workers    int
+
+	mu     sync.Mutex
+	global *bucket
+	chats  map[int64]*chatQueue
+	depth  int
+	wake   chan struct{}
+}
+
+type chatQueue struct {
+	jobs   []job
+	bucket *bucket
+}
+
+type job struct {
+	send   SendFunc
+	done   DoneFunc
+	queued time.Time
+}
+
+func New() *Sender {
+	return &Sender{
+		GlobalRate: DefaultGlobalRate,
+		ChatRate:   DefaultChatRate,
+		Workers:    DefaultWorkers,
+		chats:      make(map[int64]*chatQueue),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Enqueue ставит сообщение в очередь чата chatID. done может быть nil.
+// Безопасно вызывать из любой горутины, в том числе до Run.
+func (s *Sender) Enqueue(chatID int64, send SendFunc, done DoneFunc) {
+	now := time.Now()
+	s.mu.Lock()
+	q, ok := s.chats[chatID]
+	if !ok {
+		q = &chatQueue{bucket: newBucket(s.ChatRate, 1, now)}
+		s.chats[chatID] = q
+	}
+	if len(q.jobs) == 0 {
+		chatsWaiting.Add(1)
+	}
+	q.jobs = append(q.jobs, job{send: send, done: done, queued: now})
+	s.depth++
+	queueDepth.Set(int64(s.depth))
+	if int64(s.depth) > queueDepthMax.Value() {
+		queueDepthMax.Set(int64(s.depth))
+	}
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Len возвращает число сообщений в очереди, не считая уже отправляемых.
+func (s *Sender) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.depth
+}
+
+// Wait оценивает, сколько простоит в очереди сообщение, если поставить его в чат chatID сейчас:
+// по ограничению чата и общему ограничению, без учета токенов, накопленных в ведрах.
+func (s *Sender) Wait(chatID int64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chatLen := 0
+	if q, ok := s.chats[chatID]; ok {
+		chatLen = len(q.jobs)
+	}
+	chatWait := time.Duration(float64(chatLen) / s.ChatRate * float64(time.Second))
+	globalWait := time.Duration(float64(s.depth) / s.GlobalRate * float64(time.Second))
+	return max(chatWait, globalWait)
+}
+
+// Run отправляет сообщения из очереди, пока не отменен ctx. После отмены Run дожидается сообщений,
+// которые уже отправляются, а оставшиеся в очереди не отправляет: их DoneFunc получают ErrStopped.
+func (s *Sender) Run(ctx context.Context) {
+	work := make(chan job)
+	var workers sync.WaitGroup
+	for i := 0; i < max(s.Workers, 1); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker(work)
+		}()
+	}
+	stopped := s.dispatch(ctx, work)
+	close(work)
+	workers.Wait()
+	for _, j := range append(stopped, s.drain()...) {
+		if j.done != nil {
+			j.done(ErrStopped)
+		}
+	}
+}
+
+// dispatch раздает сообщения горутинам отправки, пока не отменен ctx. Возвращает сообщение, которое
+// успел вынуть из очереди, но не отдал на отправку.
+func (s *Sender) dispatch(ctx context.Context, work chan<- job) []job {
+	for {
+		next, wait, ok := s.next(time.Now())
+		if ok {
+			select {
+			case work <- next:
+				continue
+			case <-ctx.Done():
+				return []job{next}
+			}
+		}
+		// Пустая очередь ждет только Enqueue
+		var timeout <-chan time.Time
+		timer := time.NewTimer(wait)
+		if wait > 0 {
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-s.wake:
+		case <-timeout:
+		}
+		timer.Stop()
+	}
+}
+
+func worker(work <-chan job) {
+	for j := range work {
+		err := j.send()
+		switch {
+		case errors.Is(err, ErrSkipped):
+			skipped.Add(1)
+		case err != nil:
+			failed.Add(1)
+		default:
+			sent.Add(1)
+		}
+		waitMs.Add(time.Since(j.queued).Milliseconds())
+		if j.done != nil {
+			j.done(err)
+		}
+	}
+}
+
+// next забирает из очереди сообщение, которое можно отправить в момент now. Если ждать еще рано,
+// возвращает, сколько ждать; 0 - очередь пуста. Первым идет чат, которому раньше всех можно писать,
+// при равенстве - чат с самым старым сообщением.
+func (s *Sender) next(now time.Time) (job, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.global == nil {
+		s.global = newBucket(s.GlobalRate, s.GlobalRate, now)
+	}
+	var best *chatQueue
+	var bestReady time.Time
+	for chatID, q := range s.chats {
+		if len(q.jobs) == 0 {
+			// Ведро снова полное - о чате можно забыть, новое ведро будет таким же
+			if q.bucket.full(now) {
+				delete(s.chats, chatID)
+			}
+			continue
+		}
+		ready := q.bucket.ready(now)
+		if best == nil || ready.Before(bestReady) ||
+			ready.Equal(bestReady) && q.jobs[0].queued.Before(best.jobs[0].queued) {
+			best, bestReady = q, ready
+		}
+	}
+	if best == nil {
+		return job{}, 0, false
+	}
+	if global := s.global.ready(now); global.After(bestReady) {
+		bestReady = global
+	}
+	if bestReady.After(now) {
+		return job{}, bestReady.Sub(now), false
+	}
+	s.global.take(now)
+	best.bucket.take(now)
+	next := best.jobs[0]
+	best.jobs = best.jobs[1:]
+	if len(best.jobs) == 0 {
+		best.jobs = nil
+		chatsWaiting.Add(-1)
+	}
+	s.depth--
+	queueDepth.Set(int64(s.depth))
+	return next, 0, true
+}
+
+// drain забирает из очереди все оставшиеся сообщения.
+func (s *Sender) drain() []job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []job
+	for chatID, q := range s.chats {
+		if len(q.jobs) > 0 {
+			jobs = append(jobs, q.jobs...)
+			chatsWaiting.Add(-1)
+		}
+		delete(s.chats, chatID)
+	}
+	s.depth = 0
+	queueDepth.Set(0)
+	return jobs
+}
+
+// bucket - ведро токенов: пополняется на rate токенов в секунду, вмещает не больше burst.
+// Каждое сообщение забирает один токен.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newBucket возвращает полное ведро.
+func newBucket(rate, burst float64, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// ready возвращает, когда в ведре появится целый токен.
+func (b *bucket) ready(now time.Time) time.Time {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return now
+	}
+	return now.Add(time.Duration(math.Ceil((1 - b.tokens) / b.rate * float64(time.Second))))
+}
+
+func (b *bucket) take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+func (b *bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
@@ -0,0 +1,223 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder запоминает, в какой чат и когда ушло каждое сообщение.
+type recorder struct {
+	mu    sync.Mutex
+	chats []int64
+	times []time.Time
+	done  chan struct{}
+}
+
+func newRecorder() *recorder { return &recorder{done: make(chan struct{}, 100)} }
+
+func (r *recorder) send(chatID int64) SendFunc {
+	return func() error {
+		r.mu.Lock()
+		r.chats = append(r.chats, chatID)
+		r.times = append(r.times, time.Now())
+		r.mu.Unlock()
+		return nil
+	}
+}
+
+func (r *recorder) finished(err error) { r.done <- struct{}{} }
+
+func (r *recorder) wait(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("отправлено %d из %d", i, n)
+		}
+	}
+}
+
+func TestBucket(t *testing.T) {
+	now := time.Date(2025, 1, 16, 9, 0, 0, 0, time.UTC)
+	b := newBucket(2, 2, now)
+	assert.Equal(t, now, b.ready(now))
+	b.take(now)
+	b.take(now)
+	assert.False(t, b.full(now))
+	assert.Equal(t, now.Add(500*time.Millisecond), b.ready(now))
+	assert.Equal(t, now.Add(500*time.Millisecond), b.ready(now.Add(250*time.Millisecond)))
+	// Больше burst не накапливается
+	assert.True(t, b.full(now.Add(time.Hour)))
+	b.take(now.Add(time.Hour))
+	b.take(now.Add(time.Hour))
+	assert.Equal(t, now.Add(time.Hour+500*time.Millisecond), b.ready(now.Add(time.Hour)))
+}
+
+func TestSender_ChatRate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newRecorder()
+	s := New()
+	s.ChatRate = 10
+	for i := 0; i < 3; i++ {
+		s.Enqueue(1, r.send(1), r.finished)
+	}
+	go s.Run(ctx)
+	r.wait(t, 3)
+
+	for i := 1; i < len(r.times); i++ {
+		assert.GreaterOrEqual(t, r.times[i].Sub(r.times[i-1]), 90*time.Millisecond)
+	}
+}
+
+func TestSender_ChatDoesNotBlockOthers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newRecorder()
+	s := New()
+	s.ChatRate = 5
+	s.Workers = 1
+	for i := 0; i < 3; i++ {
+		s.Enqueue(1, r.send(1), r.finished)
+	}
+	s.Enqueue(2, r.send(2), r.finished)
+	go s.Run(ctx)
+	r.wait(t, 4)
+
+	// Чат 2 не ждет, пока уйдут все сообщения чата 1
+	assert.Equal(t, []int64{1, 2, 1, 1}, r.chats)
+}
+
+func TestSender_GlobalRate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newRecorder()
+	s := New()
+	s.GlobalRate = 5
+	start := time.Now()
+	for chatID := int64(1); chatID <= 7; chatID++ {
+		s.Enqueue(chatID, r.send(chatID), r.finished)
+	}
+	go s.Run(ctx)
+	r.wait(t, 7)
+
+	// Первые пять уходят сразу, остальные - по одному в 200 мс
+	assert.Less(t, r.times[4].Sub(start), 100*time.Millisecond)
+	assert.GreaterOrEqual(t, r.times[6].Sub(start), 390*time.Millisecond)
+}
+
+func TestSender_Done(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New()
+	results := make(chan error, 1)
+	s.Enqueue(1, func() error { return errors.New("403 Forbidden") }, func(err error) { results <- err })
+	go s.Run(ctx)
+
+	select {
+	case err := <-results:
+		assert.EqualError(t, err, "403 Forbidden")
+	case <-time.After(time.Second):
+		t.Fatal("не отправлено")
+	}
+}
+
+func TestSender_Skipped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := New()
+	results := make(chan error, 1)
+	sentBefore, failedBefore, skippedBefore := sent.Value(), failed.Value(), skipped.Value()
+	s.Enqueue(1, func() error { return ErrSkipped }, func(err error) { results <- err })
+	go s.Run(ctx)
+
+	select {
+	case err := <-results:
+		assert.ErrorIs(t, err, ErrSkipped)
+	case <-time.After(time.Second):
+		t.Fatal("не отправлено")
+	}
+	assert.Equal(t, skippedBefore+1, skipped.Value())
+	assert.Equal(t, sentBefore, sent.Value())
+	assert.Equal(t, failedBefore, failed.Value())
+}
+
+func TestSender_Stopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New()
+	results := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		s.Enqueue(1, func() error { return nil }, func(err error) { results <- err })
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(stopped)
+	}()
+	// Первое сообщение уходит сразу, остальные ждут ограничения чата
+	assert.NoError(t, <-results)
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run не остановился")
+	}
+	// Оставшиеся в очереди не отправлены, но каждое получило свой результат
+	assert.ErrorIs(t, <-results, ErrStopped)
+	assert.ErrorIs(t, <-results, ErrStopped)
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, int64(0), chatsWaiting.Value())
+}
+
+func TestSender_Wait(t *testing.T) {
+	s := New()
+	assert.Equal(t, time.Duration(0), s.Wait(1))
+	for i := 0; i < 150; i++ {
+		s.Enqueue(1, func() error { return nil }, nil)
+	}
+	s.Enqueue(2, func() error { return nil }, nil)
+	// Чат 1 ждет своей очереди по одному сообщению в секунду, чат 2 - только общей очереди
+	assert.Equal(t, 150*time.Second, s.Wait(1))
+	assert.Equal(t, time.Duration(151)*time.Second/30, s.Wait(2))
+	// Опустошить очередь, чтобы общие счетчики expvar не достались следующим тестам
+	at := time.Now()
+	for s.Len() > 0 {
+		at = at.Add(time.Second)
+		s.next(at)
+	}
+}
+
+func TestSender_QueueDepth(t *testing.T) {
+	s := New()
+	s.Enqueue(1, func() error { return nil }, nil)
+	s.Enqueue(1, func() error { return nil }, nil)
+	s.Enqueue(2, func() error { return nil }, nil)
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, int64(3), queueDepth.Value())
+	assert.GreaterOrEqual(t, queueDepthMax.Value(), int64(3))
+	assert.Equal(t, int64(2), chatsWaiting.Value())
+
+	now := time.Now()
+	_, _, ok := s.next(now)
+	assert.True(t, ok)
+	_, _, ok = s.next(now)
+	assert.True(t, ok)
+	// У чата 1 закончились токены - его второе сообщение ждет
+	_, wait, ok := s.next(now)
+	assert.False(t, ok)
+	assert.Greater(t, wait, time.Duration(0))
+	assert.Equal(t, 1, s.Len())
+	assert.Equal(t, int64(1), queueDepth.Value())
+	assert.Equal(t, int64(1), chatsWaiting.Value())
+
+	_, _, ok = s.next(now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), chatsWaiting.Value())
+	assert.Equal(t, "0", metrics.Get("queue_depth").String())
+}
@@ -0,0 +1,96 @@
+package timeparse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var fuzzSeeds = []string{
+	"12:00 сходить в магазин",
+	"2024-10-10 12:00 test",
+	"каждый день 09:00 зарядка",
+	"every mon,wed 18:30 gym",
+	"ежемесячно 2024-01-31 10:00 аренда",
+	"через 20 минут чай",
+	"через 2 дня в 10:00 отчет",
+	"+2h15m созвон",
+	"in 2h call",
+	"завтра в 9 позвонить",
+	"в пятницу в 18:00 бар",
+	"15 ноября в 10:30 врач",
+	"на следующей неделе в среду ревью",
+	"31 ноября",
+	"в",
+	"через",
+}
+
+// FuzzParse проверяет, что Parse не паникует и что результат согласован со входом:
+// разобранные слова идут в начале строки, действие - ровно оставшиеся слова,
+// а StartsWithTime узнает во входе время.
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, int64(1728561600), true)
+	}
+	f.Fuzz(func(t *testing.T, input string, unix int64, withLocation bool) {
+		now := time.Unix(unix%(1<<40), 0).UTC()
+		var loc *time.Location
+		if withLocation {
+			loc = time.FixedZone("", 3*60*60)
+		}
+		res, err := Parse(input, now, loc)
+		if err != nil {
+			return
+		}
+		tokens := strings.Fields(input)
+		if len(res.Consumed) == 0 || len(res.Consumed) >= len(tokens) {
+			t.Fatalf("consumed %q of %q", res.Consumed, tokens)
+		}
+		for i, token := range res.Consumed {
+			if tokens[i] != token {
+				t.Fatalf("consumed %q is not a prefix of %q", res.Consumed, tokens)
+			}
+		}
+		if res.Action != strings.Join(tokens[len(res.Consumed):], " ") {
+			t.Fatalf("action %q does not match the rest of %q", res.Action, tokens)
+		}
+		if res.When.Before(now) {
+			t.Fatalf("when %v is before now %v", res.When, now)
+		}
+		if loc == nil && !res.Relative {
+			t.Fatalf("absolute result %v without location", res.When)
+		}
+		if !StartsWithTime(input) {
+			t.Fatalf("parsed %q, but StartsWithTime is false", input)
+		}
+	})
+}
+
+// FuzzRecurrenceNext проверяет, что следующее срабатывание всегда позже notBefore
+// и попадает на один из выбранных дней недели.
+func FuzzRecurrenceNext(f *testing.F) {
+	f.Add(uint8(0), uint8(0), uint8(1), int64(1728561600), int64(0))
+	f.Add(uint8(1), uint8(0b0000101), uint8(0), int64(1728561600), int64(86400*40))
+	f.Add(uint8(2), uint8(0), uint8(31), int64(1706695200), int64(86400*400))
+	f.Add(uint8(3), uint8(0), uint8(29), int64(1709200800), int64(86400*800))
+	f.Fuzz(func(t *testing.T, freq, weekdayMask, day uint8, start, shift int64) {
+		rec := &Recurrence{
+			Frequency: []Frequency{Daily, Weekly, Monthly, Yearly}[freq%4],
+			Day:       int(day%31) + 1,
+		}
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if weekdayMask&(1<<d) != 0 {
+				rec.Weekdays = append(rec.Weekdays, d)
+			}
+		}
+		last := time.Unix(start%(1<<34), 0).UTC()
+		notBefore := last.Add(time.Duration(shift%(86400*3650)) * time.Second)
+		next := rec.Next(last, notBefore)
+		if !next.After(notBefore) {
+			t.Fatalf("next %v is not after %v", next, notBefore)
+		}
+		if !rec.matchesWeekday(next) {
+			t.Fatalf("next %v does not match %v", next, rec.Weekdays)
+		}
+	})
+}
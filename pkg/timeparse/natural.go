@@ -0,0 +1,153 @@
+package timeparse
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHour - во сколько напоминать, если указан только день ("послезавтра", "на следующей неделе").
+const DefaultHour = 9
+
+var monthNames = map[string]time.Month{
+	"января": time.January, "февраля": time.February, "марта": time.March,
+	"апреля": time.April, "мая": time.May, "июня": time.June,
+	"июля": time.July, "августа": time.August, "сентября": time.September,
+	"октября": time.October, "ноября": time.November, "декабря": time.December,
+}
+
+var weekdayAccusative = map[string]time.Weekday{
+	"понедельник": time.Monday, "вторник": time.Tuesday, "среду": time.Wednesday,
+	"четверг": time.Thursday, "пятницу": time.Friday, "субботу": time.Saturday,
+	"воскресенье": time.Sunday,
+}
+
+func isNatural(tokens []string) bool {
+	switch strings.ToLower(tokens[0]) {
+	case "сегодня", "завтра", "послезавтра":
+		return true
+	case "в", "во":
+		return len(tokens) > 1
+	case "на":
+		return len(tokens) > 1 && strings.HasPrefix(strings.ToLower(tokens[1]), "следующ")
+	}
+	day, err := strconv.Atoi(tokens[0])
+	return err == nil && day >= 1 && day <= 31 && len(tokens) > 1
+}
+
+// parseNatural разбирает "завтра в 9", "послезавтра", "в пятницу в 18:00",
+// "15 ноября в 10:30" и "на следующей неделе" относительно местного времени now.
+func parseNatural(tokens []string, now time.Time) (time.Time, int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var day time.Time
+	var weekday *time.Weekday
+	var todayOrTomorrow bool
+	i := 0
+	switch first := strings.ToLower(tokens[0]); first {
+	case "сегодня":
+		day, i = today, 1
+	case "завтра":
+		day, i = today.AddDate(0, 0, 1), 1
+	case "послезавтра":
+		day, i = today.AddDate(0, 0, 2), 1
+	case "в", "во":
+		if _, _, ok := parseClock(tokens[1]); ok {
+			// "в 9 позвонить" - ближайшие 9:00, сегодня или завтра
+			day, todayOrTomorrow = today, true
+			break
+		}
+		if clockFormat.MatchString(tokens[1]) {
+			return time.Time{}, 0, &Error{Part: "время", Token: tokens[1]}
+		}
+		wd, ok := weekdayAccusative[strings.ToLower(tokens[1])]
+		if !ok {
+			return time.Time{}, 0, &Error{Part: "день недели", Token: tokens[1]}
+		}
+		weekday, i = &wd, 2
+	case "на":
+		if len(tokens) < 3 || strings.ToLower(tokens[2]) != "неделе" {
+			return time.Time{}, 0, &Error{Part: "дату", Token: strings.Join(tokens[:min(3, len(tokens))], " ")}
+		}
+		// понедельник следующей недели или указанный день на ней: "на следующей неделе в среду"
+		monday := today.AddDate(0, 0, -int((today.Weekday()+6)%7)+7)
+		day, i = monday, 3
+		if i+1 < len(tokens) && isAt(tokens[i]) {
+			if wd, ok := weekdayAccusative[strings.ToLower(tokens[i+1])]; ok {
+				day = monday.AddDate(0, 0, int((wd+6)%7))
+				i += 2
+			}
+		}
+	default:
+		n, _ := strconv.Atoi(first)
+		month, ok := monthNames[strings.ToLower(tokens[1])]
+		if !ok {
+			return time.Time{}, 0, &Error{Part: "месяц", Token: tokens[1]}
+		}
+		i = 2
+		year := today.Year()
+		yearGiven := false
+		if i < len(tokens) && len(tokens[i]) == 4 {
+			if y, err := strconv.Atoi(tokens[i]); err == nil {
+				year, yearGiven = y, true
+				i++
+			}
+		}
+		day = time.Date(year, month, n, 0, 0, 0, 0, now.Location())
+		if day.Month() != month {
+			return time.Time{}, 0, &Error{Part: "дату", Token: strings.Join(tokens[:i], " ")}
+		}
+		if !yearGiven && day.Before(today) {
+			day = day.AddDate(1, 0, 0)
+		}
+	}
+
+	hour, minute := DefaultHour, 0
+	if i < len(tokens) {
+		if isAt(tokens[i]) && i+1 < len(tokens) {
+			h, m, ok := parseClock(tokens[i+1])
+			if !ok {
+				return time.Time{}, 0, &Error{Part: "время", Token: tokens[i+1]}
+			}
+			hour, minute = h, m
+			i += 2
+		} else if clockFormat.MatchString(tokens[i]) {
+			h, m, ok := parseClock(tokens[i])
+			if !ok {
+				return time.Time{}, 0, &Error{Part: "время", Token: tokens[i]}
+			}
+			hour, minute = h, m
+			i++
+		}
+	}
+
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	}
+	if todayOrTomorrow && !at(day).After(now) {
+		day = day.AddDate(0, 0, 1)
+	}
+	if weekday != nil {
+		day = today.AddDate(0, 0, int((*weekday-today.Weekday()+7)%7))
+		if !at(day).After(now) {
+			day = day.AddDate(0, 0, 7)
+		}
+	}
+	return at(day), i, nil
+}
+
+// parseClock разбирает время суток "9", "18:00" или "9:30".
+func parseClock(word string) (int, int, bool) {
+	hourPart, minutePart, hasMinutes := strings.Cut(word, ":")
+	hour, err := strconv.Atoi(hourPart)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, false
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minutePart)
+		if err != nil || len(minutePart) != 2 || minute > 59 {
+			return 0, 0, false
+		}
+	}
+	return hour, minute, true
+}
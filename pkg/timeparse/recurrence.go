@@ -0,0 +1,220 @@
+package timeparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Yearly  Frequency = "yearly"
+)
+
+// Recurrence - правило повтора. Weekdays задают дни недели для Weekly,
+// Day - день месяца для Monthly и Yearly, чтобы 31-е не сползало на 28-е после февраля.
+type Recurrence struct {
+	Frequency Frequency
+	Weekdays  []time.Weekday
+	Day       int
+}
+
+var recurrenceKeywords = map[string]Frequency{
+	"ежедневно":   Daily,
+	"еженедельно": Weekly,
+	"ежемесячно":  Monthly,
+	"ежегодно":    Yearly,
+}
+
+var recurrencePeriods = map[string]Frequency{
+	"день":   Daily,
+	"day":    Daily,
+	"неделю": Weekly,
+	"week":   Weekly,
+	"месяц":  Monthly,
+	"month":  Monthly,
+	"год":    Yearly,
+	"year":   Yearly,
+}
+
+var weekdayNames = map[string][]time.Weekday{
+	"пн": {time.Monday}, "понедельник": {time.Monday}, "mon": {time.Monday}, "monday": {time.Monday},
+	"вт": {time.Tuesday}, "вторник": {time.Tuesday}, "tue": {time.Tuesday}, "tuesday": {time.Tuesday},
+	"ср": {time.Wednesday}, "среду": {time.Wednesday}, "среда": {time.Wednesday}, "wed": {time.Wednesday}, "wednesday": {time.Wednesday},
+	"чт": {time.Thursday}, "четверг": {time.Thursday}, "thu": {time.Thursday}, "thursday": {time.Thursday},
+	"пт": {time.Friday}, "пятницу": {time.Friday}, "пятница": {time.Friday}, "fri": {time.Friday}, "friday": {time.Friday},
+	"сб": {time.Saturday}, "субботу": {time.Saturday}, "суббота": {time.Saturday}, "sat": {time.Saturday}, "saturday": {time.Saturday},
+	"вс": {time.Sunday}, "воскресенье": {time.Sunday}, "sun": {time.Sunday}, "sunday": {time.Sunday},
+	"будни":    {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"выходные": {time.Saturday, time.Sunday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+var weekdayShortNames = []string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+func isRecurrenceKeyword(word string) bool {
+	word = strings.ToLower(word)
+	if _, ok := recurrenceKeywords[word]; ok {
+		return true
+	}
+	switch word {
+	case "каждый", "каждую", "каждое", "every":
+		return true
+	}
+	return false
+}
+
+// parseRecurring разбирает "каждый день 09:00", "every mon,wed 18:30", "каждую пятницу в 18:00"
+// и "ежемесячно 2024-11-05 10:00". Первое срабатывание выравнивается по правилу.
+func parseRecurring(tokens []string, now time.Time) (Result, int, error) {
+	rec, n, err := parseRecurrence(tokens)
+	if err != nil {
+		return Result{}, 0, err
+	}
+	if n < len(tokens) && isAt(tokens[n]) {
+		n++
+	}
+	if n >= len(tokens) {
+		return Result{}, 0, ErrIncomplete
+	}
+	when, m, err := parseAbsolute(tokens[n:], now)
+	if err != nil {
+		return Result{}, 0, err
+	}
+	if rec.Day == 0 && (rec.Frequency == Monthly || rec.Frequency == Yearly) {
+		rec.Day = when.Day()
+	}
+	return Result{When: rec.Next(when, now), Recurrence: rec}, n + m, nil
+}
+
+func parseRecurrence(tokens []string) (*Recurrence, int, error) {
+	first := strings.ToLower(tokens[0])
+	if freq, ok := recurrenceKeywords[first]; ok {
+		return &Recurrence{Frequency: freq}, 1, nil
+	}
+	if len(tokens) < 2 {
+		return nil, 0, ErrRecurrence
+	}
+	if freq, ok := recurrencePeriods[strings.ToLower(tokens[1])]; ok {
+		return &Recurrence{Frequency: freq}, 2, nil
+	}
+	var weekdays []time.Weekday
+	i := 1
+	for ; i < len(tokens); i++ {
+		days, ok := parseWeekdays(tokens[i])
+		if !ok {
+			break
+		}
+		weekdays = append(weekdays, days...)
+	}
+	if len(weekdays) == 0 {
+		return nil, 0, ErrRecurrence
+	}
+	return &Recurrence{Frequency: Weekly, Weekdays: uniqueWeekdays(weekdays)}, i, nil
+}
+
+func parseWeekdays(word string) ([]time.Weekday, bool) {
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(strings.ToLower(word), ",") {
+		if name == "" {
+			continue
+		}
+		days, ok := weekdayNames[name]
+		if !ok {
+			return nil, false
+		}
+		weekdays = append(weekdays, days...)
+	}
+	return weekdays, len(weekdays) > 0
+}
+
+func uniqueWeekdays(weekdays []time.Weekday) []time.Weekday {
+	var seen [7]bool
+	for _, day := range weekdays {
+		seen[day] = true
+	}
+	unique := make([]time.Weekday, 0, len(weekdays))
+	for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday} {
+		if seen[day] {
+			unique = append(unique, day)
+		}
+	}
+	return unique
+}
+
+// Next возвращает ближайшее срабатывание по правилу, начиная с t, которое позже notBefore.
+// Шаги делаются по календарю в часовом поясе t, поэтому время на часах сохраняется при переходе на летнее время.
+func (r *Recurrence) Next(t, notBefore time.Time) time.Time {
+	for !t.After(notBefore) || !r.matchesWeekday(t) {
+		t = r.advance(t)
+	}
+	return t
+}
+
+func (r *Recurrence) matchesWeekday(t time.Time) bool {
+	if r.Frequency != Weekly || len(r.Weekdays) == 0 {
+		return true
+	}
+	for _, day := range r.Weekdays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) advance(t time.Time) time.Time {
+	switch r.Frequency {
+	case Weekly:
+		if len(r.Weekdays) > 0 {
+			return t.AddDate(0, 0, 1)
+		}
+		return t.AddDate(0, 0, 7)
+	case Monthly:
+		return addMonths(t, 1, r.Day)
+	case Yearly:
+		return addMonths(t, 12, r.Day)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// addMonths сдвигает дату на n месяцев, сохраняя исходный день месяца там, где он существует
+// (31 января -> 28/29 февраля -> 31 марта).
+func addMonths(t time.Time, n, day int) time.Time {
+	if day == 0 {
+		day = t.Day()
+	}
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), 0, 0, t.Location())
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+	if day > daysInMonth {
+		day = daysInMonth
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func (r *Recurrence) String() string {
+	switch r.Frequency {
+	case Daily:
+		return "каждый день"
+	case Weekly:
+		if len(r.Weekdays) == 0 {
+			return "каждую неделю"
+		}
+		names := make([]string, 0, len(r.Weekdays))
+		for _, day := range r.Weekdays {
+			names = append(names, weekdayShortNames[day])
+		}
+		return "каждый " + strings.Join(names, ",")
+	case Monthly:
+		return fmt.Sprintf("каждый месяц %d числа", r.Day)
+	case Yearly:
+		return "каждый год"
+	}
+	return string(r.Frequency)
+}
@@ -0,0 +1,79 @@
+package timeparse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrence_Next(t *testing.T) {
+	testTable := []struct {
+		name      string
+		rec       *Recurrence
+		last      time.Time
+		notBefore time.Time
+		want      time.Time
+	}{
+		{
+			name:      "Daily",
+			rec:       &Recurrence{Frequency: Daily},
+			last:      time.Date(2024, 10, 10, 9, 0, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 10, 10, 9, 0, 30, 0, time.UTC),
+			want:      time.Date(2024, 10, 11, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "DailyCatchUp",
+			rec:       &Recurrence{Frequency: Daily},
+			last:      time.Date(2024, 10, 10, 9, 0, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 10, 13, 12, 0, 0, 0, time.UTC),
+			want:      time.Date(2024, 10, 14, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Weekdays",
+			rec:       &Recurrence{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}},
+			last:      time.Date(2024, 10, 16, 18, 30, 0, 0, time.UTC), // среда
+			notBefore: time.Date(2024, 10, 16, 18, 30, 0, 0, time.UTC),
+			want:      time.Date(2024, 10, 21, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "Weekly",
+			rec:       &Recurrence{Frequency: Weekly},
+			last:      time.Date(2024, 10, 16, 18, 30, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 10, 16, 18, 30, 0, 0, time.UTC),
+			want:      time.Date(2024, 10, 23, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyEndOfMonth",
+			rec:       &Recurrence{Frequency: Monthly, Day: 31},
+			last:      time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+			want:      time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "MonthlyRestoresDay",
+			rec:       &Recurrence{Frequency: Monthly, Day: 31},
+			last:      time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			want:      time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Yearly",
+			rec:       &Recurrence{Frequency: Yearly, Day: 29},
+			last:      time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			notBefore: time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+			want:      time.Date(2025, 2, 28, 10, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rec.Next(tt.last, tt.notBefore))
+		})
+	}
+}
+
+func TestRecurrence_String(t *testing.T) {
+	assert.Equal(t, "каждый день", (&Recurrence{Frequency: Daily}).String())
+	assert.Equal(t, "каждый пн,ср", (&Recurrence{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}}).String())
+	assert.Equal(t, "каждый месяц 5 числа", (&Recurrence{Frequency: Monthly, Day: 5}).String())
+}
@@ -0,0 +1,145 @@
+package timeparse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var compactDuration = regexp.MustCompile(`^\+?((\d+)(d|h|m|д|ч|м))+$`)
+var compactDurationPart = regexp.MustCompile(`(\d+)(d|h|m|д|ч|м)`)
+
+// maxOffset ограничивает смещение, чтобы "+99999999d" не переполнял time.Duration. Проверяются и
+// отдельные части, и их сумма после каждого сложения: иначе несколько частей по maxOffset переполнятся.
+const maxOffset = 100 * 365 * 24 * time.Hour
+
+var durationUnits = map[string]time.Duration{
+	"минуту": time.Minute, "минуты": time.Minute, "минут": time.Minute, "мин": time.Minute,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"час": time.Hour, "часа": time.Hour, "часов": time.Hour,
+	"hour": time.Hour, "hours": time.Hour,
+	"день": 24 * time.Hour, "дня": 24 * time.Hour, "дней": 24 * time.Hour,
+	"day": 24 * time.Hour, "days": 24 * time.Hour,
+	"неделю": 7 * 24 * time.Hour, "недели": 7 * 24 * time.Hour, "недель": 7 * 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+func isRelative(tokens []string) bool {
+	first := strings.ToLower(tokens[0])
+	return first == "через" || first == "in" || compactDuration.MatchString(first)
+}
+
+// parseRelative разбирает "+30m", "2h15m", "in 2h", "через 3 часа", "через полчаса"
+// и "через 2 дня в 10:00". Время суток требует часового пояса, само смещение - нет.
+func parseRelative(tokens []string, now time.Time, loc *time.Location) (Result, int, error) {
+	offset, i, ok := parseDuration(tokens)
+	if !ok {
+		return Result{}, 0, ErrDuration
+	}
+	res := Result{Relative: true, Offset: offset}
+	if i+1 < len(tokens) && isAt(tokens[i]) && clockFormat.MatchString(tokens[i+1]) {
+		clock, err := time.Parse("15:04", tokens[i+1])
+		if err != nil {
+			return Result{}, 0, ErrTimeFormat
+		}
+		if loc == nil {
+			return Result{}, 0, ErrNeedLocation
+		}
+		day := now.In(loc).Add(offset)
+		res.When = time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		res.Relative = false
+		return res, i + 2, nil
+	}
+	res.When = now.Add(offset).Truncate(time.Second)
+	if loc != nil {
+		res.When = res.When.In(loc)
+	} else {
+		res.When = res.When.UTC()
+	}
+	return res, i, nil
+}
+
+// parseDuration разбирает длительность в начале tokens и возвращает число разобранных слов.
+func parseDuration(tokens []string) (time.Duration, int, bool) {
+	var offset time.Duration
+	i := 0
+	if first := strings.ToLower(tokens[0]); first == "через" || first == "in" {
+		i = 1
+	}
+	for i < len(tokens) {
+		word := strings.ToLower(tokens[i])
+		if compactDuration.MatchString(word) {
+			d, ok := parseCompactDuration(word)
+			if !ok {
+				return 0, 0, false
+			}
+			offset += d
+			if offset > maxOffset {
+				return 0, 0, false
+			}
+			i++
+			continue
+		}
+		// "через час", "через полчаса" - единица без числа допустима только первой,
+		// иначе "через 2 часа день рождения" съест слово из действия
+		if offset == 0 {
+			if word == "полчаса" {
+				offset += 30 * time.Minute
+				i++
+				continue
+			}
+			if unit, ok := durationUnits[word]; ok {
+				offset += unit
+				i++
+				continue
+			}
+		}
+		n, err := strconv.Atoi(word)
+		if err != nil || n < 0 || i+1 >= len(tokens) {
+			break
+		}
+		unit, ok := durationUnits[strings.ToLower(tokens[i+1])]
+		if !ok {
+			break
+		}
+		if time.Duration(n) > maxOffset/unit {
+			return 0, 0, false
+		}
+		offset += time.Duration(n) * unit
+		if offset > maxOffset {
+			return 0, 0, false
+		}
+		i += 2
+	}
+	if offset <= 0 || offset > maxOffset {
+		return 0, 0, false
+	}
+	return offset, i, true
+}
+
+func parseCompactDuration(word string) (time.Duration, bool) {
+	var d time.Duration
+	for _, match := range compactDurationPart.FindAllStringSubmatch(word, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, false
+		}
+		unit := time.Minute
+		switch match[2] {
+		case "d", "д":
+			unit = 24 * time.Hour
+		case "h", "ч":
+			unit = time.Hour
+		}
+		if time.Duration(n) > maxOffset/unit {
+			return 0, false
+		}
+		d += time.Duration(n) * unit
+		// Каждая часть не больше maxOffset, поэтому сумма, проверяемая после каждого сложения, не переполнится
+		if d > maxOffset {
+			return 0, false
+		}
+	}
+	return d, true
+}
@@ -0,0 +1,193 @@
+// Package timeparse разбирает выражения времени из команд вида
+// "/remindme завтра в 9 позвонить маме": абсолютные ("12:00", "2024-10-10 12:00"),
+// относительные ("через 20 минут", "+2h15m"), словесные на русском ("в пятницу в 18:00")
+// и повторяющиеся ("каждый день 09:00", "every mon,wed 18:30").
+//
+// Пакет не зависит от бота: та же грамматика нужна CLI и HTTP API.
+package timeparse
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrIncomplete - во входной строке нет времени или действия.
+	ErrIncomplete = errors.New("не хватает времени или действия")
+	// ErrNeedLocation - выражение привязано к часам ("12:00", "завтра"), а часовой пояс не передан.
+	ErrNeedLocation = errors.New("неизвестен часовой пояс")
+	// ErrPast - указанное время уже прошло.
+	ErrPast = errors.New("ошибка: Указанное время уже прошло. Укажите время в будущем")
+	// ErrTimeFormat - время или дата похожи на HH:mm / YYYY-MM-DD, но не разбираются.
+	ErrTimeFormat = errors.New("ошибка при разборе времени. Формат должен быть HH:mm")
+	// ErrRecurrence - не удалось понять правило повтора после "каждый"/"every".
+	ErrRecurrence = errors.New("не понимаю, как часто повторять напоминание")
+	// ErrDuration - после "через"/"in" не нашлось длительности.
+	ErrDuration = errors.New("не понимаю, через сколько напомнить. Например: /remindme через 20 минут позвонить или /remindme +2h15m позвонить")
+)
+
+// Error сообщает, какую часть фразы не удалось разобрать.
+type Error struct {
+	Part  string
+	Token string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("не поняла %s «%s»", e.Part, e.Token)
+}
+
+// Result - разобранная команда.
+type Result struct {
+	// When - момент срабатывания в переданном часовом поясе (в UTC, если он не передан).
+	When time.Time
+	// Action - текст напоминания, то что осталось после выражения времени.
+	Action string
+	// Recurrence - правило повтора или nil для разовых напоминаний.
+	Recurrence *Recurrence
+	// Consumed - слова, из которых было разобрано время.
+	Consumed []string
+	// Relative - время задано смещением от now и не зависит от часового пояса.
+	Relative bool
+	// Offset - смещение для относительных напоминаний.
+	Offset time.Duration
+}
+
+var clockFormat = regexp.MustCompile(`^\d{1,2}[:]\d{2}$`)
+var dateFormat = regexp.MustCompile(`^\d{4}[-]\d{2}[-]\d{2}`)
+
+// Parse разбирает input относительно момента now. loc - часовой пояс пользователя;
+// если он nil, принимаются только относительные выражения, для остальных возвращается ErrNeedLocation.
+func Parse(input string, now time.Time, loc *time.Location) (Result, error) {
+	res, n, err := parse(input, now, loc)
+	if err != nil {
+		return Result{}, err
+	}
+	if n >= len(res.Consumed) {
+		return Result{}, ErrIncomplete
+	}
+	tokens := res.Consumed
+	res.Consumed = tokens[:n]
+	res.Action = strings.Join(tokens[n:], " ")
+	return res, nil
+}
+
+// ParseTime разбирает строку, в которой есть только время, без действия: "через 2 часа",
+// "завтра в 18:00". Повторы не принимаются. Нужна, когда действие уже известно, например при переносе напоминания.
+func ParseTime(input string, now time.Time, loc *time.Location) (Result, error) {
+	res, n, err := parse(input, now, loc)
+	if err != nil {
+		return Result{}, err
+	}
+	if res.Recurrence != nil {
+		return Result{}, &Error{Part: "время", Token: strings.TrimSpace(input)}
+	}
+	if n < len(res.Consumed) {
+		return Result{}, &Error{Part: "время", Token: strings.Join(res.Consumed[n:], " ")}
+	}
+	return res, nil
+}
+
+// ParsePrefix разбирает выражение времени в начале input, как Parse, но действие после него
+// необязательно: Action может быть пустым. Нужна, когда текст напоминания уже есть и его можно не менять.
+func ParsePrefix(input string, now time.Time, loc *time.Location) (Result, error) {
+	res, n, err := parse(input, now, loc)
+	if err != nil {
+		return Result{}, err
+	}
+	tokens := res.Consumed
+	res.Consumed = tokens[:n]
+	res.Action = strings.Join(tokens[n:], " ")
+	return res, nil
+}
+
+// StartsWithTime сообщает, что input начинается с выражения времени, а не с обычного текста:
+// "завтра ...", "в пятницу ...", "12:00 ...", "через 2 часа ...". Само выражение при этом
+// может быть ошибочным ("25:00"), это покажет Parse. Нужна, чтобы понять, меняет ли
+// пользователь время или только текст.
+func StartsWithTime(input string) bool {
+	tokens := strings.Fields(input)
+	if len(tokens) == 0 {
+		return false
+	}
+	first := strings.ToLower(tokens[0])
+	if isRelative(tokens) || isRecurrenceKeyword(first) || clockFormat.MatchString(first) || dateFormat.MatchString(first) {
+		return true
+	}
+	if !isNatural(tokens) {
+		return false
+	}
+	switch first {
+	case "сегодня", "завтра", "послезавтра", "на":
+		return true
+	case "в", "во":
+		_, isWeekday := weekdayAccusative[strings.ToLower(tokens[1])]
+		return isWeekday || tokens[1][0] >= '0' && tokens[1][0] <= '9'
+	}
+	_, isMonth := monthNames[strings.ToLower(tokens[1])]
+	return isMonth
+}
+
+// parse разбирает выражение времени в начале input. Возвращает число слов, из которых оно разобрано;
+// Consumed в результате содержит все слова input.
+func parse(input string, now time.Time, loc *time.Location) (Result, int, error) {
+	tokens := strings.Fields(input)
+	if len(tokens) == 0 {
+		return Result{}, 0, ErrIncomplete
+	}
+	var res Result
+	var n int
+	var err error
+	if isRelative(tokens) {
+		res, n, err = parseRelative(tokens, now, loc)
+	} else if loc == nil {
+		return Result{}, 0, ErrNeedLocation
+	} else if isRecurrenceKeyword(tokens[0]) {
+		res, n, err = parseRecurring(tokens, now.In(loc))
+	} else if isNatural(tokens) {
+		res.When, n, err = parseNatural(tokens, now.In(loc))
+	} else {
+		res.When, n, err = parseAbsolute(tokens, now.In(loc))
+	}
+	if err != nil {
+		return Result{}, 0, err
+	}
+	if res.When.Before(now) {
+		return Result{}, 0, ErrPast
+	}
+	res.Consumed = tokens
+	return res, n, nil
+}
+
+// parseAbsolute разбирает "12:00" (сегодня или завтра, если время прошло) и "2024-10-10 12:00".
+func parseAbsolute(tokens []string, now time.Time) (time.Time, int, error) {
+	if clockFormat.MatchString(tokens[0]) {
+		clock, err := time.Parse("15:04", tokens[0])
+		if err != nil {
+			return time.Time{}, 0, ErrTimeFormat
+		}
+		when := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if when.Before(now) {
+			when = when.AddDate(0, 0, 1)
+		}
+		return when, 1, nil
+	}
+	if dateFormat.MatchString(tokens[0]) && len(tokens) > 1 && clockFormat.MatchString(tokens[1]) {
+		when, err := time.ParseInLocation("2006-01-02 15:04", tokens[0]+" "+tokens[1], now.Location())
+		if err != nil {
+			return time.Time{}, 0, ErrTimeFormat
+		}
+		return when, 2, nil
+	}
+	return time.Time{}, 0, fmt.Errorf("неправильный формат даты или времени: не поняла «%s»", tokens[0])
+}
+
+func isAt(word string) bool {
+	switch strings.ToLower(word) {
+	case "в", "во", "at":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,483 @@
+package timeparse
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("", 3*60*60)
+	// четверг, 10 октября 2024, 12:00 по местному времени
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, loc)
+	testTable := []struct {
+		name         string
+		input        string
+		loc          *time.Location
+		wantErr      error
+		wantErrText  string
+		wantWhen     time.Time
+		wantAction   string
+		wantConsumed []string
+		wantRec      *Recurrence
+		wantRelative bool
+	}{
+		{
+			name:         "Clock",
+			input:        "23:59 test",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 10, 23, 59, 0, 0, loc),
+			wantAction:   "test",
+			wantConsumed: []string{"23:59"},
+		},
+		{
+			name:         "ClockPassedRollsOver",
+			input:        "00:01 test",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 0, 1, 0, 0, loc),
+			wantAction:   "test",
+			wantConsumed: []string{"00:01"},
+		},
+		{
+			name:         "DateTime",
+			input:        "  2025-10-16 12:00   сходить в   магазин ",
+			loc:          loc,
+			wantWhen:     time.Date(2025, 10, 16, 12, 0, 0, 0, loc),
+			wantAction:   "сходить в магазин",
+			wantConsumed: []string{"2025-10-16", "12:00"},
+		},
+		{
+			name:        "InvalidClock",
+			input:       "25:00 test",
+			loc:         loc,
+			wantErr:     ErrTimeFormat,
+			wantErrText: "ошибка при разборе времени. Формат должен быть HH:mm",
+		},
+		{
+			name:        "InvalidFormat",
+			input:       "1200 test",
+			loc:         loc,
+			wantErrText: "неправильный формат даты или времени: не поняла «1200»",
+		},
+		{
+			name:    "Past",
+			input:   "1995-05-25 12:00 test",
+			loc:     loc,
+			wantErr: ErrPast,
+		},
+		{
+			name:    "NoAction",
+			input:   "12:00",
+			loc:     loc,
+			wantErr: ErrIncomplete,
+		},
+		{
+			name:    "Empty",
+			input:   "   ",
+			loc:     loc,
+			wantErr: ErrIncomplete,
+		},
+		{
+			name:    "NeedLocation",
+			input:   "завтра в 9 позвонить",
+			wantErr: ErrNeedLocation,
+		},
+		{
+			name:         "RelativeWithoutLocation",
+			input:        "через 20 минут выключить плиту",
+			wantWhen:     time.Date(2024, 10, 10, 9, 20, 0, 0, time.UTC),
+			wantAction:   "выключить плиту",
+			wantConsumed: []string{"через", "20", "минут"},
+			wantRelative: true,
+		},
+		{
+			name:         "RelativeCompact",
+			input:        "+2h15m созвон",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 10, 14, 15, 0, 0, loc),
+			wantAction:   "созвон",
+			wantConsumed: []string{"+2h15m"},
+			wantRelative: true,
+		},
+		{
+			name:         "RelativeEnglish",
+			input:        "in 2h call mom",
+			wantWhen:     time.Date(2024, 10, 10, 11, 0, 0, 0, time.UTC),
+			wantAction:   "call mom",
+			wantConsumed: []string{"in", "2h"},
+			wantRelative: true,
+		},
+		{
+			name:         "RelativeKeepsActionWords",
+			input:        "через 2 часа день рождения",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 10, 14, 0, 0, 0, loc),
+			wantAction:   "день рождения",
+			wantConsumed: []string{"через", "2", "часа"},
+			wantRelative: true,
+		},
+		{
+			name:         "RelativeHalfHour",
+			input:        "через полчаса чай",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 10, 12, 30, 0, 0, loc),
+			wantAction:   "чай",
+			wantConsumed: []string{"через", "полчаса"},
+			wantRelative: true,
+		},
+		{
+			name:         "RelativeDaysAtClock",
+			input:        "через 2 дня в 10:00 отчет",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 12, 10, 0, 0, 0, loc),
+			wantAction:   "отчет",
+			wantConsumed: []string{"через", "2", "дня", "в", "10:00"},
+		},
+		{
+			name:    "RelativeClockNeedsLocation",
+			input:   "через 2 дня в 10:00 отчет",
+			wantErr: ErrNeedLocation,
+		},
+		{
+			name:    "RelativeNoDuration",
+			input:   "через сколько-то чай",
+			wantErr: ErrDuration,
+		},
+		{
+			name:    "RelativeOverflow",
+			input:   "+9999999999999d чай",
+			wantErr: ErrDuration,
+		},
+		{
+			// Шесть частей по 100 лет переполняют int64 и дают снова положительную сумму меньше maxOffset
+			name:    "RelativeSumOverflow",
+			input:   "+36500d36500d36500d36500d36500d36500d чай",
+			wantErr: ErrDuration,
+		},
+		{
+			name:    "RelativeSumOverflowWords",
+			input:   "через +36500d +36500d +36500d 36500 дней 36500 дней 36500 дней чай",
+			wantErr: ErrDuration,
+		},
+		{
+			name:    "RelativeSumOverMax",
+			input:   "через 36500 дней 1 день чай",
+			wantErr: ErrDuration,
+		},
+		{
+			name:         "NaturalTomorrow",
+			input:        "завтра в 9 позвонить",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 9, 0, 0, 0, loc),
+			wantAction:   "позвонить",
+			wantConsumed: []string{"завтра", "в", "9"},
+		},
+		{
+			name:         "NaturalDefaultHour",
+			input:        "послезавтра сдать отчет",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 12, DefaultHour, 0, 0, 0, loc),
+			wantAction:   "сдать отчет",
+			wantConsumed: []string{"послезавтра"},
+		},
+		{
+			name:         "NaturalWeekday",
+			input:        "в пятницу в 18:00 бар",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 18, 0, 0, 0, loc),
+			wantAction:   "бар",
+			wantConsumed: []string{"в", "пятницу", "в", "18:00"},
+		},
+		{
+			name:         "NaturalSameWeekdayPassed",
+			input:        "в четверг в 10:00 бар",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 17, 10, 0, 0, 0, loc),
+			wantAction:   "бар",
+			wantConsumed: []string{"в", "четверг", "в", "10:00"},
+		},
+		{
+			name:         "NaturalHourOnly",
+			input:        "в 9 зарядка",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 9, 0, 0, 0, loc),
+			wantAction:   "зарядка",
+			wantConsumed: []string{"в", "9"},
+		},
+		{
+			name:         "NaturalDayMonth",
+			input:        "15 ноября в 10:30 врач",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 11, 15, 10, 30, 0, 0, loc),
+			wantAction:   "врач",
+			wantConsumed: []string{"15", "ноября", "в", "10:30"},
+		},
+		{
+			name:         "NaturalDayMonthNextYear",
+			input:        "1 марта весна",
+			loc:          loc,
+			wantWhen:     time.Date(2025, 3, 1, DefaultHour, 0, 0, 0, loc),
+			wantAction:   "весна",
+			wantConsumed: []string{"1", "марта"},
+		},
+		{
+			name:         "NaturalNextWeek",
+			input:        "на следующей неделе отпуск",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 14, DefaultHour, 0, 0, 0, loc),
+			wantAction:   "отпуск",
+			wantConsumed: []string{"на", "следующей", "неделе"},
+		},
+		{
+			name:         "NaturalNextWeekWeekday",
+			input:        "на следующей неделе в среду в 15:00 ревью",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 16, 15, 0, 0, 0, loc),
+			wantAction:   "ревью",
+			wantConsumed: []string{"на", "следующей", "неделе", "в", "среду", "в", "15:00"},
+		},
+		{
+			name:        "NaturalBadTime",
+			input:       "завтра в 25:00 позвонить",
+			loc:         loc,
+			wantErrText: "не поняла время «25:00»",
+		},
+		{
+			name:        "NaturalBadMonth",
+			input:       "15 нобря врач",
+			loc:         loc,
+			wantErrText: "не поняла месяц «нобря»",
+		},
+		{
+			name:        "NaturalBadWeekday",
+			input:       "в пятницк бар",
+			loc:         loc,
+			wantErrText: "не поняла день недели «пятницк»",
+		},
+		{
+			name:        "NaturalBadDate",
+			input:       "31 ноября врач",
+			loc:         loc,
+			wantErrText: "не поняла дату «31 ноября»",
+		},
+		{
+			name:         "RecurringDaily",
+			input:        "каждый день 09:00 зарядка",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 9, 0, 0, 0, loc),
+			wantAction:   "зарядка",
+			wantConsumed: []string{"каждый", "день", "09:00"},
+			wantRec:      &Recurrence{Frequency: Daily},
+		},
+		{
+			name:         "RecurringWeekdays",
+			input:        "every wed,mon 18:30 gym",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 14, 18, 30, 0, 0, loc),
+			wantAction:   "gym",
+			wantConsumed: []string{"every", "wed,mon", "18:30"},
+			wantRec:      &Recurrence{Frequency: Weekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}},
+		},
+		{
+			name:         "RecurringNaturalClock",
+			input:        "каждую пятницу в 18:00 бар",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 11, 18, 0, 0, 0, loc),
+			wantAction:   "бар",
+			wantConsumed: []string{"каждую", "пятницу", "в", "18:00"},
+			wantRec:      &Recurrence{Frequency: Weekly, Weekdays: []time.Weekday{time.Friday}},
+		},
+		{
+			name:         "RecurringMonthlyFromDate",
+			input:        "Ежемесячно 2024-10-31 10:00 аренда",
+			loc:          loc,
+			wantWhen:     time.Date(2024, 10, 31, 10, 0, 0, 0, loc),
+			wantAction:   "аренда",
+			wantConsumed: []string{"Ежемесячно", "2024-10-31", "10:00"},
+			wantRec:      &Recurrence{Frequency: Monthly, Day: 31},
+		},
+		{
+			name:    "RecurringUnknown",
+			input:   "каждый вечер 18:00 бар",
+			loc:     loc,
+			wantErr: ErrRecurrence,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Parse(tt.input, now, tt.loc)
+			if tt.wantErr != nil || tt.wantErrText != "" {
+				if tt.wantErr != nil {
+					assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				}
+				if tt.wantErrText != "" {
+					assert.EqualError(t, err, tt.wantErrText)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.wantWhen.Equal(res.When), "want %v, got %v", tt.wantWhen, res.When)
+			assert.Equal(t, tt.wantAction, res.Action)
+			assert.Equal(t, tt.wantConsumed, res.Consumed)
+			assert.Equal(t, tt.wantRec, res.Recurrence)
+			assert.Equal(t, tt.wantRelative, res.Relative)
+		})
+	}
+}
+
+func TestParseNaturalError(t *testing.T) {
+	_, err := Parse("15 нобря врач", time.Now(), time.UTC)
+	var parseErr *Error
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, "месяц", parseErr.Part)
+	assert.Equal(t, "нобря", parseErr.Token)
+}
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("", 3*60*60)
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, loc)
+	testTable := []struct {
+		name        string
+		input       string
+		loc         *time.Location
+		wantErr     error
+		wantErrText string
+		wantWhen    time.Time
+	}{
+		{
+			name:     "Relative",
+			input:    "через 2 часа",
+			wantWhen: now.Add(2 * time.Hour),
+		},
+		{
+			name:     "RelativeWithoutLocation",
+			input:    "+30m",
+			loc:      nil,
+			wantWhen: now.Add(30 * time.Minute),
+		},
+		{
+			name:     "Natural",
+			input:    "завтра в 18:00",
+			loc:      loc,
+			wantWhen: time.Date(2024, 10, 11, 18, 0, 0, 0, loc),
+		},
+		{
+			name:     "Absolute",
+			input:    "2024-10-12 09:30",
+			loc:      loc,
+			wantWhen: time.Date(2024, 10, 12, 9, 30, 0, 0, loc),
+		},
+		{
+			name:        "TrailingWords",
+			input:       "завтра в 18:00 позвонить",
+			loc:         loc,
+			wantErrText: "не поняла время «позвонить»",
+		},
+		{
+			name:        "Recurring",
+			input:       "каждый день 09:00",
+			loc:         loc,
+			wantErrText: "не поняла время «каждый день 09:00»",
+		},
+		{
+			name:    "Empty",
+			input:   "  ",
+			loc:     loc,
+			wantErr: ErrIncomplete,
+		},
+		{
+			name:    "NeedLocation",
+			input:   "завтра",
+			loc:     nil,
+			wantErr: ErrNeedLocation,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParseTime(tt.input, now, tt.loc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if tt.wantErrText != "" {
+				assert.EqualError(t, err, tt.wantErrText)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.wantWhen.Equal(res.When), "%s != %s", tt.wantWhen, res.When)
+			assert.Empty(t, res.Action)
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	loc := time.FixedZone("", 3*60*60)
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, loc)
+	testTable := []struct {
+		name           string
+		input          string
+		wantWhen       time.Time
+		wantAction     string
+		wantRecurrence bool
+	}{
+		{
+			name:     "OnlyTime",
+			input:    "завтра в 18:00",
+			wantWhen: time.Date(2024, 10, 11, 18, 0, 0, 0, loc),
+		},
+		{
+			name:       "TimeAndAction",
+			input:      "завтра в 18:00 позвонить",
+			wantWhen:   time.Date(2024, 10, 11, 18, 0, 0, 0, loc),
+			wantAction: "позвонить",
+		},
+		{
+			name:           "Recurring",
+			input:          "каждый день 09:00",
+			wantWhen:       time.Date(2024, 10, 11, 9, 0, 0, 0, loc),
+			wantRecurrence: true,
+		},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ParsePrefix(tt.input, now, loc)
+			assert.NoError(t, err)
+			assert.True(t, tt.wantWhen.Equal(res.When), "%s != %s", tt.wantWhen, res.When)
+			assert.Equal(t, tt.wantAction, res.Action)
+			assert.Equal(t, tt.wantRecurrence, res.Recurrence != nil)
+		})
+	}
+	_, err := ParsePrefix("завтра", now, nil)
+	assert.ErrorIs(t, err, ErrNeedLocation)
+}
+
+func TestStartsWithTime(t *testing.T) {
+	testTable := []struct {
+		input string
+		want  bool
+	}{
+		{input: "12:00 купить хлеб", want: true},
+		{input: "25:00 купить хлеб", want: true},
+		{input: "2024-10-10 12:00 врач", want: true},
+		{input: "через 2 часа", want: true},
+		{input: "+30m", want: true},
+		{input: "каждый день 09:00 зарядка", want: true},
+		{input: "завтра позвонить", want: true},
+		{input: "в пятницу бар", want: true},
+		{input: "в 9 позвонить", want: true},
+		{input: "на следующей неделе врач", want: true},
+		{input: "15 ноября врач", want: true},
+		{input: "купить хлеб", want: false},
+		{input: "в магазин за хлебом", want: false},
+		{input: "на почту", want: false},
+		{input: "3 яблока", want: false},
+		{input: "", want: false},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, StartsWithTime(tt.input))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Команда gen готовит boundaries.tab.gz для tzresolver из границ часовых поясов timezone-boundary-builder
+// (https://github.com/evansiroky/timezone-boundary-builder, лицензия ODbL 1.0), которые модуль tzf-rel-lite
+// уже хранит в сокращенном виде. Здесь границы упрощаются еще раз, до точности около километра, морские
+// зоны Etc/GMT отбрасываются (в открытом море tzresolver считает зону по долготе сам), а результат
+// записывается текстом и сжимается gzip.
+//
+// Команда - отдельный модуль, чтобы зависимости генератора не попали в сам бот. Запуск из pkg/tzresolver:
+//
+//	go generate
+//
+// Чтобы обновить границы, поднимите версию tzf-rel-lite в gen/go.mod.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	tzfrellite "github.com/ringsaturn/tzf-rel-lite"
+	pb "github.com/ringsaturn/tzf/gen/go/tzf/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// point - вершина контура в градусах.
+type point struct {
+	lat, lon float64
+}
+
+func main() {
+	out := flag.String("o", "boundaries.tab.gz", "куда записать границы")
+	tolerance := flag.Float64("tolerance", 0.01, "допустимое отклонение упрощенного контура, градусов")
+	decimals := flag.Int("decimals", 3, "знаков после запятой в координатах")
+	flag.Parse()
+
+	var timezones pb.Timezones
+	if err := proto.Unmarshal(tzfrellite.LiteData, &timezones); err != nil {
+		log.Fatal(err)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	zw, err := gzip.NewWriterLevel(f, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w := bufio.NewWriter(zw)
+	fmt.Fprintf(w, "# Границы часовых поясов timezone-boundary-builder %s, упрощенные до %g° (около %.0f м).\n",
+		timezones.Version, *tolerance, *tolerance*111000)
+	fmt.Fprintln(w, "# Данные: https://github.com/evansiroky/timezone-boundary-builder, лицензия ODbL 1.0.")
+	fmt.Fprintln(w, "# Файл создан командой pkg/tzresolver/gen, вручную не редактируется.")
+	fmt.Fprintln(w, "#")
+	fmt.Fprintln(w, "# Одна строка - один многоугольник: зона tzdb, внешний контур и вырезы в нем через табуляцию.")
+	fmt.Fprintln(w, "# Контур - вершины \"широта,долгота\" через пробел, замыкается сам.")
+	polygons, vertices := 0, 0
+	for _, tz := range timezones.Timezones {
+		if strings.HasPrefix(tz.Name, "Etc/") {
+			continue
+		}
+		for _, polygon := range tz.Polygons {
+			outer := simplify(polygon.Points, *tolerance, *decimals)
+			if outer == nil {
+				continue
+			}
+			w.WriteString(tz.Name)
+			writeRing(w, outer)
+			vertices += len(outer)
+			for _, hole := range polygon.Holes {
+				if inner := simplify(hole.Points, *tolerance, *decimals); inner != nil {
+					writeRing(w, inner)
+					vertices += len(inner)
+				}
+			}
+			w.WriteByte('\n')
+			polygons++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%s: %d многоугольников, %d вершин", *out, polygons, vertices)
+}
+
+func writeRing(w *bufio.Writer, ring []point) {
+	w.WriteByte('\t')
+	for i, p := range ring {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.WriteString(strconv.FormatFloat(p.lat, 'f', -1, 64))
+		w.WriteByte(',')
+		w.WriteString(strconv.FormatFloat(p.lon, 'f', -1, 64))
+	}
+}
+
+// simplify упрощает замкнутый контур алгоритмом Дугласа-Пекера и округляет координаты.
+// nil - от контура остались меньше трех вершин, то есть он мельче tolerance.
+func simplify(points []*pb.Point, tolerance float64, decimals int) []point {
+	ring := make([]point, 0, len(points))
+	for _, p := range points {
+		ring = append(ring, point{lat: float64(p.Lat), lon: float64(p.Lng)})
+	}
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+	// Замкнутый контур делится на две ломаные по самой дальней от начала вершине: у ломаной
+	// с совпадающими концами Дуглас-Пекер выбросил бы все вершины
+	far, farDistance := 0, -1.0
+	for i, p := range ring {
+		if d := math.Hypot(p.lat-ring[0].lat, p.lon-ring[0].lon); d > farDistance {
+			far, farDistance = i, d
+		}
+	}
+	first := douglasPeucker(ring[:far+1], tolerance)
+	second := douglasPeucker(append(append([]point{}, ring[far:]...), ring[0]), tolerance)
+	simplified := append(first[:len(first)-1], second[:len(second)-1]...)
+
+	scale := math.Pow(10, float64(decimals))
+	var rounded []point
+	for _, p := range simplified {
+		p = point{lat: math.Round(p.lat*scale) / scale, lon: math.Round(p.lon*scale) / scale}
+		if len(rounded) == 0 || rounded[len(rounded)-1] != p {
+			rounded = append(rounded, p)
+		}
+	}
+	if len(rounded) > 1 && rounded[0] == rounded[len(rounded)-1] {
+		rounded = rounded[:len(rounded)-1]
+	}
+	if len(rounded) < 3 {
+		return nil
+	}
+	return rounded
+}
+
+// douglasPeucker оставляет концы ломаной и те вершины, без которых она отклонилась бы больше чем на tolerance.
+func douglasPeucker(line []point, tolerance float64) []point {
+	if len(line) < 3 {
+		return line
+	}
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+	type span struct{ from, to int }
+	stack := []span{{0, len(line) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		farthest, farDistance := -1, 0.0
+		for i := s.from + 1; i < s.to; i++ {
+			if d := segmentDistance(line[i], line[s.from], line[s.to]); d > farDistance {
+				farthest, farDistance = i, d
+			}
+		}
+		if farthest >= 0 && farDistance > tolerance {
+			keep[farthest] = true
+			stack = append(stack, span{s.from, farthest}, span{farthest, s.to})
+		}
+	}
+	var kept []point
+	for i, k := range keep {
+		if k {
+			kept = append(kept, line[i])
+		}
+	}
+	return kept
+}
+
+// segmentDistance - расстояние от p до отрезка ab в градусах, как на плоской карте.
+func segmentDistance(p, a, b point) float64 {
+	dLon, dLat := b.lon-a.lon, b.lat-a.lat
+	if dLon == 0 && dLat == 0 {
+		return math.Hypot(p.lon-a.lon, p.lat-a.lat)
+	}
+	t := ((p.lon-a.lon)*dLon + (p.lat-a.lat)*dLat) / (dLon*dLon + dLat*dLat)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.lon-(a.lon+t*dLon), p.lat-(a.lat+t*dLat))
+}
@@ -0,0 +1,194 @@
+package tzresolver
+
+import (
+	"strings"
+	"sync"
+)
+
+// City - город для ручного выбора часового пояса.
+type City struct {
+	Name string
+	Zone string
+}
+
+// Region - группа городов для клавиатуры выбора часового пояса.
+type Region struct {
+	Name   string
+	Cities []City
+}
+
+// Regions - города, из которых можно выбрать часовой пояс кнопками.
+var Regions = []Region{
+	{Name: "Россия", Cities: []City{
+		{"Калининград", "Europe/Kaliningrad"},
+		{"Москва", "Europe/Moscow"},
+		{"Самара", "Europe/Samara"},
+		{"Волгоград", "Europe/Volgograd"},
+		{"Екатеринбург", "Asia/Yekaterinburg"},
+		{"Омск", "Asia/Omsk"},
+		{"Новосибирск", "Asia/Novosibirsk"},
+		{"Красноярск", "Asia/Krasnoyarsk"},
+		{"Иркутск", "Asia/Irkutsk"},
+		{"Якутск", "Asia/Yakutsk"},
+		{"Владивосток", "Asia/Vladivostok"},
+		{"Магадан", "Asia/Magadan"},
+		{"Камчатка", "Asia/Kamchatka"},
+	}},
+	{Name: "Европа", Cities: []City{
+		{"Лондон", "Europe/London"},
+		{"Лиссабон", "Europe/Lisbon"},
+		{"Париж", "Europe/Paris"},
+		{"Берлин", "Europe/Berlin"},
+		{"Мадрид", "Europe/Madrid"},
+		{"Рим", "Europe/Rome"},
+		{"Прага", "Europe/Prague"},
+		{"Варшава", "Europe/Warsaw"},
+		{"Хельсинки", "Europe/Helsinki"},
+		{"Рига", "Europe/Riga"},
+		{"Киев", "Europe/Kyiv"},
+		{"Минск", "Europe/Minsk"},
+		{"Кишинев", "Europe/Chisinau"},
+		{"Афины", "Europe/Athens"},
+		{"Стамбул", "Europe/Istanbul"},
+	}},
+	{Name: "Азия", Cities: []City{
+		{"Тбилиси", "Asia/Tbilisi"},
+		{"Ереван", "Asia/Yerevan"},
+		{"Баку", "Asia/Baku"},
+		{"Иерусалим", "Asia/Jerusalem"},
+		{"Дубай", "Asia/Dubai"},
+		{"Тегеран", "Asia/Tehran"},
+		{"Ташкент", "Asia/Tashkent"},
+		{"Алматы", "Asia/Almaty"},
+		{"Бишкек", "Asia/Bishkek"},
+		{"Душанбе", "Asia/Dushanbe"},
+		{"Дели", "Asia/Kolkata"},
+		{"Бангкок", "Asia/Bangkok"},
+		{"Сингапур", "Asia/Singapore"},
+		{"Пекин", "Asia/Shanghai"},
+		{"Сеул", "Asia/Seoul"},
+		{"Токио", "Asia/Tokyo"},
+	}},
+	{Name: "Америка", Cities: []City{
+		{"Нью-Йорк", "America/New_York"},
+		{"Торонто", "America/Toronto"},
+		{"Чикаго", "America/Chicago"},
+		{"Денвер", "America/Denver"},
+		{"Финикс", "America/Phoenix"},
+		{"Лос-Анджелес", "America/Los_Angeles"},
+		{"Анкоридж", "America/Anchorage"},
+		{"Гонолулу", "Pacific/Honolulu"},
+		{"Мехико", "America/Mexico_City"},
+		{"Богота", "America/Bogota"},
+		{"Лима", "America/Lima"},
+		{"Сантьяго", "America/Santiago"},
+		{"Буэнос-Айрес", "America/Argentina/Buenos_Aires"},
+		{"Сан-Паулу", "America/Sao_Paulo"},
+	}},
+	{Name: "Африка", Cities: []City{
+		{"Касабланка", "Africa/Casablanca"},
+		{"Аккра", "Africa/Accra"},
+		{"Лагос", "Africa/Lagos"},
+		{"Каир", "Africa/Cairo"},
+		{"Найроби", "Africa/Nairobi"},
+		{"Йоханнесбург", "Africa/Johannesburg"},
+	}},
+	{Name: "Океания", Cities: []City{
+		{"Перт", "Australia/Perth"},
+		{"Аделаида", "Australia/Adelaide"},
+		{"Брисбен", "Australia/Brisbane"},
+		{"Сидней", "Australia/Sydney"},
+		{"Мельбурн", "Australia/Melbourne"},
+		{"Окленд", "Pacific/Auckland"},
+		{"Фиджи", "Pacific/Fiji"},
+	}},
+}
+
+// aliases - другие названия городов, которых нет на клавиатуре.
+var aliases = map[string]string{
+	"санкт-петербург": "Europe/Moscow",
+	"петербург":       "Europe/Moscow",
+	"питер":           "Europe/Moscow",
+	"спб":             "Europe/Moscow",
+	"мск":             "Europe/Moscow",
+	"казань":          "Europe/Moscow",
+	"нижний новгород": "Europe/Moscow",
+	"ростов-на-дону":  "Europe/Moscow",
+	"краснодар":       "Europe/Moscow",
+	"сочи":            "Europe/Moscow",
+	"мурманск":        "Europe/Moscow",
+	"архангельск":     "Europe/Moscow",
+	"симферополь":     "Europe/Simferopol",
+	"саратов":         "Europe/Saratov",
+	"ульяновск":       "Europe/Ulyanovsk",
+	"астрахань":       "Europe/Astrakhan",
+	"челябинск":       "Asia/Yekaterinburg",
+	"уфа":             "Asia/Yekaterinburg",
+	"пермь":           "Asia/Yekaterinburg",
+	"тюмень":          "Asia/Yekaterinburg",
+	"барнаул":         "Asia/Barnaul",
+	"томск":           "Asia/Tomsk",
+	"кемерово":        "Asia/Novokuznetsk",
+	"новокузнецк":     "Asia/Novokuznetsk",
+	"чита":            "Asia/Chita",
+	"хабаровск":       "Asia/Vladivostok",
+	"южно-сахалинск":  "Asia/Sakhalin",
+	"петропавловск-камчатский": "Asia/Kamchatka",
+	"анадырь":                  "Asia/Anadyr",
+	"одесса":                   "Europe/Kyiv",
+	"харьков":                  "Europe/Kyiv",
+	"львов":                    "Europe/Kyiv",
+	"астана":                   "Asia/Almaty",
+	"kiev":                     "Europe/Kyiv",
+	"saint petersburg":         "Europe/Moscow",
+	"st petersburg":            "Europe/Moscow",
+	"petropavlovsk-kamchatsky": "Asia/Kamchatka",
+}
+
+var (
+	namesOnce sync.Once
+	names     map[string]string
+)
+
+// Lookup ищет зону по названию города (по-русски или по-английски) или по имени зоны IANA
+// без учета регистра: "Москва", "moscow", "new york", "europe/moscow".
+func Lookup(name string) (string, bool) {
+	namesOnce.Do(buildNames)
+	zone, ok := names[normalizeName(name)]
+	return zone, ok
+}
+
+func buildNames() {
+	names = make(map[string]string)
+	add := func(name, zone string) {
+		key := normalizeName(name)
+		if _, exists := names[key]; !exists {
+			names[key] = zone
+		}
+	}
+	for _, region := range Regions {
+		for _, city := range region.Cities {
+			add(city.Name, city.Zone)
+		}
+	}
+	for name, zone := range aliases {
+		add(name, zone)
+	}
+	// Встроенные таблицы проверяются тестами, поэтому ошибки разбора здесь не ожидаются
+	zones, _ := parseZoneTab(zoneTab)
+	for _, zone := range zones {
+		add(zone.Name, zone.Name)
+		add(zone.Name[strings.LastIndex(zone.Name, "/")+1:], zone.Name)
+	}
+	cities, _ := parseZoneTab(citiesTab)
+	for _, city := range cities {
+		add(city.Comment, city.Name)
+	}
+}
+
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "ё", "е")
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,331 @@
+// Package tzresolver определяет часовой пояс по координатам без сети и API-ключей.
+//
+// Сначала точка ищется в границах зон на суше (boundaries.tab.gz). Это границы проекта
+// timezone-boundary-builder (https://github.com/evansiroky/timezone-boundary-builder),
+// упрощенные командой gen до точности около километра, поэтому ошибиться можно только
+// у самой границы. Данные распространяются по лицензии Open Database License (ODbL) 1.0.
+//
+// Точки у берега, срезанного упрощением, и в прибрежных водах относятся к зоне ближайшей
+// опорной точки из базы tzdb (zone.tab): для каждой зоны там указан ее главный город,
+// а зоны, которые одним городом покрывают целую страну (Индия, Китай, европейская часть
+// России), дополнены крупными городами из cities.tab. Точкам в открытом океане, далеко
+// от любого города, назначается морская зона Etc/GMT по долготе.
+package tzresolver
+
+//go:generate sh -c "cd gen && go run . -o ../boundaries.tab.gz"
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//go:embed zone.tab
+var zoneTab []byte
+
+//go:embed cities.tab
+var citiesTab []byte
+
+//go:embed boundaries.tab.gz
+var boundariesGz []byte
+
+// maxDistanceKm - дальше этого от ближайшего города точка считается открытым морем.
+const maxDistanceKm = 2000
+
+const earthRadiusKm = 6371
+
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+// Zone - зона tzdb и координаты опорного города. Comment в zone.tab поясняет,
+// какую часть страны покрывает зона, а в cities.tab это название города.
+type Zone struct {
+	Name      string
+	Country   string
+	Latitude  float64
+	Longitude float64
+	Comment   string
+}
+
+type Resolver struct {
+	zones  []Zone
+	points []Zone
+	areas  []area
+}
+
+// area - многоугольник зоны из boundaries.tab.gz: внешний контур и вырезы в нем (анклавы
+// других зон, озера). Вершины хранятся как есть, в градусах: точке нужно только понять,
+// по какую сторону контура она лежит, и искажение плоской проекции тут не мешает.
+type area struct {
+	name   string
+	outer  ring
+	holes  []ring
+	minLat float64
+	maxLat float64
+	minLon float64
+	maxLon float64
+	size   float64
+}
+
+type ring struct {
+	lats []float64
+	lons []float64
+}
+
+// New разбирает встроенные таблицы зон.
+func New() (*Resolver, error) {
+	zones, err := parseZoneTab(zoneTab)
+	if err != nil {
+		return nil, err
+	}
+	cities, err := parseZoneTab(citiesTab)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(boundariesGz))
+	if err != nil {
+		return nil, err
+	}
+	boundariesTab, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	areas, err := parseBoundaries(boundariesTab)
+	if err != nil {
+		return nil, err
+	}
+	points := append(append([]Zone{}, zones...), cities...)
+	return &Resolver{zones: zones, points: points, areas: areas}, nil
+}
+
+// Zones возвращает все известные зоны в порядке zone.tab (по коду страны).
+func (r *Resolver) Zones() []Zone {
+	return r.zones
+}
+
+// GetZone возвращает имя зоны IANA для координат, например "Europe/Berlin".
+func (r *Resolver) GetZone(lat, lon float64) (string, error) {
+	if math.IsNaN(lat) || math.IsNaN(lon) || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return "", ErrInvalidCoordinates
+	}
+	if name, ok := r.areaZone(lat, lon); ok {
+		return name, nil
+	}
+	best := -1
+	bestDistance := math.Inf(1)
+	for i, point := range r.points {
+		d := distanceKm(lat, lon, point.Latitude, point.Longitude)
+		if d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 || bestDistance > maxDistanceKm {
+		return nauticalZone(lon), nil
+	}
+	return r.points[best].Name, nil
+}
+
+// areaZone ищет самый маленький многоугольник, в который попала точка. Границы соседних
+// зон после упрощения могут слегка заходить друг на друга, тогда выигрывает меньшая зона.
+func (r *Resolver) areaZone(lat, lon float64) (string, bool) {
+	var found *area
+	for i := range r.areas {
+		a := &r.areas[i]
+		if a.contains(lat, lon) && (found == nil || a.size < found.size) {
+			found = a
+		}
+	}
+	if found == nil {
+		return "", false
+	}
+	return found.name, true
+}
+
+// contains - точка внутри внешнего контура и не попала ни в один вырез.
+func (a *area) contains(lat, lon float64) bool {
+	if lat < a.minLat || lat > a.maxLat || lon < a.minLon || lon > a.maxLon {
+		return false
+	}
+	if !a.outer.contains(lat, lon) {
+		return false
+	}
+	for _, hole := range a.holes {
+		if hole.contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains проверяет точку трассировкой луча: луч на восток пересекает контур нечетное
+// число раз, только если точка внутри.
+func (r *ring) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r.lats)-1; i < len(r.lats); j, i = i, i+1 {
+		if (r.lats[i] > lat) != (r.lats[j] > lat) &&
+			lon < r.lons[i]+(lat-r.lats[i])*(r.lons[j]-r.lons[i])/(r.lats[j]-r.lats[i]) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// nauticalZone - морская зона шириной 15° по долготе. Знак в именах Etc/GMT обратный: UTC+3 это Etc/GMT-3.
+func nauticalZone(lon float64) string {
+	offset := int(math.Round(lon / 15))
+	if offset == 0 {
+		return "Etc/GMT"
+	}
+	return fmt.Sprintf("Etc/GMT%+d", -offset)
+}
+
+// distanceKm - расстояние по большому кругу (формула гаверсинусов).
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func parseZoneTab(data []byte) ([]Zone, error) {
+	var zones []Zone
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("bad zone line %q", line)
+		}
+		lat, lon, err := parseISO6709(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fields[2], err)
+		}
+		zone := Zone{Name: fields[2], Country: fields[0], Latitude: lat, Longitude: lon}
+		if len(fields) > 3 {
+			zone.Comment = fields[3]
+		}
+		zones = append(zones, zone)
+	}
+	return zones, scanner.Err()
+}
+
+// parseBoundaries разбирает строки "зона<TAB>контур<TAB>вырез...", где контур -
+// вершины "широта,долгота" через пробел.
+func parseBoundaries(data []byte) ([]area, error) {
+	var areas []area
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("bad boundary line %q", line)
+		}
+		name := fields[0]
+		outer, err := parseRing(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		a := area{
+			name:   name,
+			outer:  outer,
+			minLat: math.Inf(1), maxLat: math.Inf(-1),
+			minLon: math.Inf(1), maxLon: math.Inf(-1),
+		}
+		for _, field := range fields[2:] {
+			hole, err := parseRing(field)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			a.holes = append(a.holes, hole)
+		}
+		for i := range outer.lats {
+			a.minLat, a.maxLat = math.Min(a.minLat, outer.lats[i]), math.Max(a.maxLat, outer.lats[i])
+			a.minLon, a.maxLon = math.Min(a.minLon, outer.lons[i]), math.Max(a.maxLon, outer.lons[i])
+		}
+		// Площадь по формуле шнурков - только чтобы сравнивать многоугольники между собой
+		for i, j := 0, len(outer.lats)-1; i < len(outer.lats); j, i = i, i+1 {
+			a.size += outer.lons[j]*outer.lats[i] - outer.lons[i]*outer.lats[j]
+		}
+		a.size = math.Abs(a.size) / 2
+		areas = append(areas, a)
+	}
+	return areas, scanner.Err()
+}
+
+func parseRing(s string) (ring, error) {
+	var r ring
+	for _, vertex := range strings.Fields(s) {
+		latStr, lonStr, _ := strings.Cut(vertex, ",")
+		lat, err := strconv.ParseFloat(latStr, 64)
+		if err != nil {
+			return ring{}, fmt.Errorf("bad vertex %q", vertex)
+		}
+		lon, err := strconv.ParseFloat(lonStr, 64)
+		if err != nil {
+			return ring{}, fmt.Errorf("bad vertex %q", vertex)
+		}
+		r.lats = append(r.lats, lat)
+		r.lons = append(r.lons, lon)
+	}
+	if len(r.lats) < 3 {
+		return ring{}, errors.New("polygon needs at least 3 vertices")
+	}
+	return r, nil
+}
+
+// parseISO6709 разбирает координаты вида ±DDMM±DDDMM или ±DDMMSS±DDDMMSS.
+func parseISO6709(s string) (float64, float64, error) {
+	split := strings.IndexAny(s[1:], "+-") + 1
+	if split <= 0 {
+		return 0, 0, fmt.Errorf("bad coordinates %q", s)
+	}
+	lat, err := parseDegrees(s[:split], 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	lon, err := parseDegrees(s[split:], 3)
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+func parseDegrees(s string, degreeDigits int) (float64, error) {
+	sign := 1.0
+	if s[0] == '-' {
+		sign = -1
+	}
+	digits := s[1:]
+	if len(digits) != degreeDigits+2 && len(digits) != degreeDigits+4 {
+		return 0, fmt.Errorf("bad coordinate %q", s)
+	}
+	value := 0.0
+	for i, scale := 0, 1.0; i < len(digits); scale *= 60 {
+		width := 2
+		if i == 0 {
+			width = degreeDigits
+		}
+		n, err := strconv.Atoi(digits[i : i+width])
+		if err != nil {
+			return 0, fmt.Errorf("bad coordinate %q", s)
+		}
+		value += float64(n) / scale
+		i += width
+	}
+	return sign * value, nil
+}
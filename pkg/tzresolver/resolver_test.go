@@ -0,0 +1,181 @@
+package tzresolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_GetZone(t *testing.T) {
+	r, err := New()
+	assert.NoError(t, err)
+	testTable := []struct {
+		name     string
+		lat, lon float64
+		want     string
+		wantErr  bool
+	}{
+		{name: "Berlin", lat: 52.52, lon: 13.40, want: "Europe/Berlin"},
+		{name: "Moscow", lat: 55.75, lon: 37.62, want: "Europe/Moscow"},
+		{name: "Kyiv", lat: 50.45, lon: 30.52, want: "Europe/Kyiv"},
+		{name: "NewYork", lat: 40.71, lon: -74.00, want: "America/New_York"},
+		{name: "Mumbai", lat: 19.07, lon: 72.88, want: "Asia/Kolkata"},
+		{name: "Kathmandu", lat: 27.70, lon: 85.32, want: "Asia/Kathmandu"},
+		{name: "Sydney", lat: -33.87, lon: 151.21, want: "Australia/Sydney"},
+		{name: "Novosibirsk", lat: 55.03, lon: 82.92, want: "Asia/Novosibirsk"},
+		{name: "PacificOcean", lat: -50, lon: -140, want: "Etc/GMT+9"},
+		{name: "SouthAtlantic", lat: -35, lon: -20, want: "Etc/GMT+1"},
+		{name: "GulfOfGuinea", lat: 0, lon: 0, want: "Africa/Accra"},
+		{name: "Munich", lat: 48.14, lon: 11.58, want: "Europe/Berlin"},
+		{name: "Lhasa", lat: 29.65, lon: 91.10, want: "Asia/Shanghai"},
+		{name: "Houston", lat: 29.76, lon: -95.37, want: "America/Chicago"},
+		// У границ зон ближайший главный город бывает по другую сторону границы
+		{name: "Vigo", lat: 42.24, lon: -8.72, want: "Europe/Madrid"},
+		{name: "Badajoz", lat: 38.88, lon: -6.97, want: "Europe/Madrid"},
+		{name: "Olivenza", lat: 38.685, lon: -7.10, want: "Europe/Madrid"},
+		{name: "Elvas", lat: 38.88, lon: -7.16, want: "Europe/Lisbon"},
+		{name: "Porto", lat: 41.15, lon: -8.61, want: "Europe/Lisbon"},
+		{name: "Gibraltar", lat: 36.14, lon: -5.35, want: "Europe/Gibraltar"},
+		{name: "Amarillo", lat: 35.22, lon: -101.83, want: "America/Chicago"},
+		{name: "Lubbock", lat: 33.58, lon: -101.85, want: "America/Chicago"},
+		{name: "ElPaso", lat: 31.76, lon: -106.49, want: "America/Denver"},
+		{name: "Goodland", lat: 39.35, lon: -101.71, want: "America/Denver"},
+		{name: "TubaCity", lat: 36.13, lon: -111.24, want: "America/Denver"},
+		{name: "Flagstaff", lat: 35.20, lon: -111.65, want: "America/Phoenix"},
+		{name: "SecondMesa", lat: 35.80, lon: -110.50, want: "America/Phoenix"},
+		{name: "LasVegas", lat: 36.17, lon: -115.14, want: "America/Los_Angeles"},
+		{name: "Chattanooga", lat: 35.05, lon: -85.31, want: "America/New_York"},
+		{name: "Evansville", lat: 37.97, lon: -87.57, want: "America/Chicago"},
+		{name: "Gary", lat: 41.59, lon: -87.35, want: "America/Chicago"},
+		{name: "Louisville", lat: 38.25, lon: -85.76, want: "America/Kentucky/Louisville"},
+		{name: "Detroit", lat: 42.33, lon: -83.05, want: "America/Detroit"},
+		{name: "Miami", lat: 25.76, lon: -80.19, want: "America/New_York"},
+		{name: "Brest", lat: 52.10, lon: 23.73, want: "Europe/Minsk"},
+		{name: "Terespol", lat: 52.08, lon: 23.62, want: "Europe/Warsaw"},
+		{name: "Lviv", lat: 49.84, lon: 24.03, want: "Europe/Kyiv"},
+		{name: "Tobolsk", lat: 58.20, lon: 68.25, want: "Asia/Yekaterinburg"},
+		{name: "Ishim", lat: 56.11, lon: 69.49, want: "Asia/Yekaterinburg"},
+		{name: "Isilkul", lat: 54.91, lon: 71.27, want: "Asia/Omsk"},
+		{name: "SaoPauloDeOlivenca", lat: -3.38, lon: -68.87, want: "America/Manaus"},
+		{name: "Leticia", lat: -4.21, lon: -69.94, want: "America/Bogota"},
+		{name: "MountGambier", lat: -37.83, lon: 140.78, want: "Australia/Adelaide"},
+		{name: "BrokenHill", lat: -31.95, lon: 141.45, want: "Australia/Broken_Hill"},
+		{name: "Kisangani", lat: 0.52, lon: 25.19, want: "Africa/Lubumbashi"},
+		{name: "CiudadJuarez", lat: 31.69, lon: -106.42, want: "America/Ciudad_Juarez"},
+		{name: "Kenora", lat: 49.77, lon: -94.49, want: "America/Winnipeg"},
+		{name: "Baarle", lat: 51.4390, lon: 4.9260, want: "Europe/Brussels"},
+		{name: "Tornio", lat: 65.85, lon: 24.15, want: "Europe/Helsinki"},
+		{name: "Invalid", lat: 91, lon: 0, wantErr: true},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, err := r.GetZone(tt.lat, tt.lon)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCoordinates)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, zone)
+			_, err = time.LoadLocation(zone)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseISO6709(t *testing.T) {
+	lat, lon, err := parseISO6709("+5230+01322")
+	assert.NoError(t, err)
+	assert.InDelta(t, 52.5, lat, 1e-9)
+	assert.InDelta(t, 13.3667, lon, 1e-3)
+
+	lat, lon, err = parseISO6709("-332203+1510510")
+	assert.NoError(t, err)
+	assert.InDelta(t, -33.3675, lat, 1e-3)
+	assert.InDelta(t, 151.0861, lon, 1e-3)
+
+	_, _, err = parseISO6709("+52")
+	assert.Error(t, err)
+}
+
+func TestResolver_AllZonesLoad(t *testing.T) {
+	r, err := New()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, r.Zones())
+	for _, zone := range r.points {
+		_, err := time.LoadLocation(zone.Name)
+		assert.NoError(t, err, zone.Name)
+	}
+}
+
+func TestResolver_AreasKnown(t *testing.T) {
+	r, err := New()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, r.areas)
+	known := make(map[string]bool)
+	for _, zone := range r.zones {
+		known[zone.Name] = true
+	}
+	for _, a := range r.areas {
+		assert.True(t, known[a.name], a.name)
+		_, err := time.LoadLocation(a.name)
+		assert.NoError(t, err, a.name)
+	}
+}
+
+func TestParseBoundaries(t *testing.T) {
+	areas, err := parseBoundaries([]byte("# comment\nTest/Square\t0,0 0,4 4,4 4,0\t1,1 1,2 2,2 2,1\n"))
+	assert.NoError(t, err)
+	assert.Len(t, areas, 1)
+	assert.InDelta(t, 16, areas[0].size, 1e-9)
+	assert.True(t, areas[0].contains(3, 3))
+	assert.False(t, areas[0].contains(1.5, 1.5), "точка в вырезе")
+	assert.False(t, areas[0].contains(1, 5))
+	assert.False(t, areas[0].contains(-1, 1))
+
+	_, err = parseBoundaries([]byte("Test/Line\t0,0 1,1\n"))
+	assert.Error(t, err)
+	_, err = parseBoundaries([]byte("Test/Bad\t0,0 1,x 2,2\n"))
+	assert.Error(t, err)
+	_, err = parseBoundaries([]byte("Test/BadHole\t0,0 0,4 4,4\t1,1 1,2\n"))
+	assert.Error(t, err)
+}
+
+func TestLookup(t *testing.T) {
+	testTable := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{name: "Москва", want: "Europe/Moscow", wantOk: true},
+		{name: "moscow", want: "Europe/Moscow", wantOk: true},
+		{name: "  Europe/Moscow ", want: "Europe/Moscow", wantOk: true},
+		{name: "нью йорк", want: "America/New_York", wantOk: true},
+		{name: "New York", want: "America/New_York", wantOk: true},
+		{name: "Ростов на Дону", want: "Europe/Moscow", wantOk: true},
+		{name: "Мумбаи", wantOk: false},
+		{name: "Mumbai", want: "Asia/Kolkata", wantOk: true},
+		{name: "Калининград", want: "Europe/Kaliningrad", wantOk: true},
+		{name: "Атлантида", wantOk: false},
+	}
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, ok := Lookup(tt.name)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, zone)
+		})
+	}
+}
+
+func TestRegionsZonesLoad(t *testing.T) {
+	for _, region := range Regions {
+		for _, city := range region.Cities {
+			_, err := time.LoadLocation(city.Zone)
+			assert.NoError(t, err, city.Name)
+		}
+	}
+	for name, zone := range aliases {
+		_, err := time.LoadLocation(zone)
+		assert.NoError(t, err, name)
+	}
+}